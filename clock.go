@@ -0,0 +1,35 @@
+package main
+
+import "time"
+
+// clock measures the time spent playing a game, leaving out pauses.
+type clock struct {
+	elapsed time.Duration // time played before the current run
+	started time.Time     // start of the current run, zero while paused
+}
+
+func (c *clock) running() bool {
+	return !c.started.IsZero()
+}
+
+func (c *clock) resume() {
+	if !c.running() {
+		c.started = time.Now()
+	}
+}
+
+func (c *clock) pause() {
+	if c.running() {
+		c.elapsed += time.Since(c.started)
+		c.started = time.Time{}
+	}
+}
+
+// seconds returns the whole seconds played so far.
+func (c *clock) seconds() int {
+	d := c.elapsed
+	if c.running() {
+		d += time.Since(c.started)
+	}
+	return int(d / time.Second)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/pack"
+)
+
+var (
+	remove = flag.Bool("remove", false, "remove duplicates, keeping the first of each puzzle")
+)
+
+func init() {
+	flag.Parse()
+}
+
+// puzzle is a grid found in a level file, or in a binary pack at index.
+type puzzle struct {
+	g     *grid.Grid
+	file  string
+	index int // in the pack, or -1 for level files
+}
+
+func (p puzzle) String() string {
+	if p.index == -1 {
+		return p.file
+	}
+	return p.file + "#" + strconv.Itoa(p.index+1)
+}
+
+// main reports the puzzles of the level pack directories, level files and
+// binary packs given as arguments that repeat an earlier puzzle, rotated or
+// reflected or not.
+func main() {
+	if flag.NArg() == 0 {
+		log.Fatal("usage: dedupe [-remove] pack...")
+	}
+	var puzzles []puzzle
+	for _, name := range flag.Args() {
+		found, err := scan(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		puzzles = append(puzzles, found...)
+	}
+
+	first := make(map[string]puzzle)
+	dupes := make(map[string]map[int]bool) // binary pack indexes to remove
+	count := 0
+	for _, p := range puzzles {
+		hash := p.g.Hash()
+		orig, ok := first[hash]
+		if !ok {
+			first[hash] = p
+			continue
+		}
+		count++
+		fmt.Println(p.String() + " duplicates " + orig.String())
+		if !*remove {
+			continue
+		}
+		if p.index == -1 {
+			if err := os.Remove(p.file); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+		if dupes[p.file] == nil {
+			dupes[p.file] = make(map[int]bool)
+		}
+		dupes[p.file][p.index] = true
+	}
+
+	for file, indexes := range dupes {
+		if err := rewritePack(file, puzzles, indexes); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Println(strconv.Itoa(count) + " duplicates in " + strconv.Itoa(len(puzzles)) + " puzzles")
+}
+
+// scan returns the puzzles of a level pack directory, a binary pack or a
+// level file in any format. Directories holding packs, like levels/, are
+// scanned a pack at a time. Files in directories that are not numbered json
+// levels are skipped, so images and binary packs are only read when named.
+func scan(name string) ([]puzzle, error) {
+	st, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if st.IsDir() {
+		files, err := pack.Levels(name)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range files {
+			files[i] = filepath.Join(name, f)
+		}
+		subdirs, err := packDirs(name)
+		if err != nil {
+			return nil, err
+		}
+		var puzzles []puzzle
+		for _, f := range append(files, subdirs...) {
+			found, err := scan(f)
+			if err != nil {
+				return nil, err
+			}
+			puzzles = append(puzzles, found...)
+		}
+		return puzzles, nil
+	}
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if grid.Detect(data) == grid.FormatBinary {
+		grids, err := grid.ReadPack(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.New(name + ": " + err.Error())
+		}
+		puzzles := make([]puzzle, len(grids))
+		for i, g := range grids {
+			puzzles[i] = puzzle{g: g, file: name, index: i}
+		}
+		return puzzles, nil
+	}
+	g, _, err := grid.ReadAny(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New(name + ": " + err.Error())
+	}
+	return []puzzle{{g: g, file: name, index: -1}}, nil
+}
+
+// rewritePack writes the binary pack file again without the grids at
+// indexes.
+func rewritePack(file string, puzzles []puzzle, indexes map[int]bool) error {
+	var keep []*grid.Grid
+	for _, p := range puzzles {
+		if p.file == file && !indexes[p.index] {
+			keep = append(keep, p.g)
+		}
+	}
+	var b bytes.Buffer
+	if err := grid.WritePack(&b, keep); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, b.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// packDirs returns the directories in dir, numbered packs first in pack order.
+func packDirs(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		if f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return pack.Less(names[i], names[j]) })
+	for i, name := range names {
+		names[i] = filepath.Join(dir, name)
+	}
+	return names, nil
+}
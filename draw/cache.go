@@ -0,0 +1,57 @@
+package draw
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ostlerc/nurikabe/grid"
+)
+
+// Cache keeps png images of level files in Dir. Images are named by a hash of
+// the level file, the theme and the tile size, so an edited level gets a new
+// image. The solution and violations options are not part of the name.
+type Cache struct {
+	Dir     string
+	Options Options
+}
+
+// Image returns the image of a level file, drawing it and saving it in the
+// cache when needed. The image is returned even if it can not be saved.
+func (c *Cache) Image(file string) (image.Image, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum(data)
+	name := filepath.Join(c.Dir, hex.EncodeToString(sum[:])+"-"+
+		strings.Replace(c.Options.theme().Name, " ", "-", -1)+"-"+strconv.Itoa(c.Options.cell())+".png")
+	if f, err := os.Open(name); err == nil {
+		img, err := png.Decode(f)
+		f.Close()
+		if err == nil {
+			return img, nil
+		}
+	}
+
+	g, _, err := grid.ReadAny(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	img := Image(g, c.Options)
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return img, err
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return img, err
+	}
+	return img, ioutil.WriteFile(name, b.Bytes(), 0600)
+}
@@ -0,0 +1,174 @@
+// Package draw renders grids as images outside of the qml app, in the themes
+// the app is drawn with.
+package draw
+
+import (
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/validator"
+)
+
+const DefaultCell = 40
+
+// Theme holds the colors of the app and of the images drawn like it, written
+// as #rrggbb. The qml files read them from the "theme" context property.
+type Theme struct {
+	Name       string
+	Background string // window and page background
+	Text       string
+	Button     string
+	ButtonText string
+	Selected   string // the current choice among buttons
+	Tile       string // open tile
+	TileText   string // clue on an open tile
+	Wall       string // closed tile
+	Border     string
+	Cursor     string // border of the keyboard selected tile
+	Error      string // open tile breaking a rule
+	WallError  string // closed tile breaking a rule
+	Solution   string // wall of the solution over an open tile, in images
+}
+
+var Themes = []*Theme{
+	{
+		Name:       "light",
+		Background: "#ffffff",
+		Text:       "#000000",
+		Button:     "#b0c4de",
+		ButtonText: "#000000",
+		Selected:   "#4682b4",
+		Tile:       "#ffffff",
+		TileText:   "#000000",
+		Wall:       "#000000",
+		Border:     "#000000",
+		Cursor:     "#4682b4",
+		Error:      "#fa8072",
+		WallError:  "#8b0000",
+		Solution:   "#a0a0a0",
+	},
+	{
+		Name:       "dark",
+		Background: "#202124",
+		Text:       "#e8eaed",
+		Button:     "#3c4a5c",
+		ButtonText: "#e8eaed",
+		Selected:   "#5b7fa6",
+		Tile:       "#5f6368",
+		TileText:   "#ffffff",
+		Wall:       "#0b0b0c",
+		Border:     "#202124",
+		Cursor:     "#8ab4f8",
+		Error:      "#c5665a",
+		WallError:  "#7a1f1f",
+		Solution:   "#35373a",
+	},
+	{
+		Name:       "high contrast",
+		Background: "#000000",
+		Text:       "#ffff00",
+		Button:     "#000000",
+		ButtonText: "#ffff00",
+		Selected:   "#0000c0",
+		Tile:       "#ffffff",
+		TileText:   "#000000",
+		Wall:       "#000000",
+		Border:     "#ffff00",
+		Cursor:     "#00ffff",
+		Error:      "#ff00ff",
+		WallError:  "#a000a0",
+		Solution:   "#808080",
+	},
+	{
+		// highlights from the Okabe-Ito palette, told apart with any color vision
+		Name:       "colorblind",
+		Background: "#ffffff",
+		Text:       "#000000",
+		Button:     "#56b4e9",
+		ButtonText: "#000000",
+		Selected:   "#0072b2",
+		Tile:       "#ffffff",
+		TileText:   "#000000",
+		Wall:       "#000000",
+		Border:     "#000000",
+		Cursor:     "#0072b2",
+		Error:      "#e69f00",
+		WallError:  "#d55e00",
+		Solution:   "#a0a0a0",
+	},
+}
+
+// ThemeByName returns the theme called name.
+func ThemeByName(name string) (*Theme, bool) {
+	for _, t := range Themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Options change how a grid is drawn. The zero value draws the grid as it is
+// with the first theme.
+type Options struct {
+	Cell       int    // tile size in pixels, DefaultCell if 0
+	Theme      *Theme // Themes[0] if nil
+	Solution   []bool // closed tiles of a solution to show, or nil
+	Violations bool   // highlight tiles breaking a rule
+}
+
+func (o *Options) cell() int {
+	if o.Cell > 0 {
+		return o.Cell
+	}
+	return DefaultCell
+}
+
+// stroke returns the width of tile borders, also used as the image margin.
+func (o *Options) stroke() int {
+	if s := o.cell() / 20; s > 1 {
+		return s
+	}
+	return 1
+}
+
+func (o *Options) theme() *Theme {
+	if o.Theme != nil {
+		return o.Theme
+	}
+	return Themes[0]
+}
+
+// tile is how a tile of a grid is drawn.
+type tile struct {
+	fill  string
+	count int
+	dot   bool
+}
+
+// tiles returns how every tile of g is drawn with o.
+func tiles(g *grid.Grid, o *Options) []tile {
+	t := o.theme()
+	var violations []validator.Violation
+	if o.Violations {
+		violations = validator.Violations(g)
+	}
+	ret := make([]tile, g.Rows()*g.Columns())
+	for i := range ret {
+		open := g.Open(i)
+		broken := violations != nil && violations[i] != 0
+		ret[i].count = g.Count(i)
+		ret[i].dot = open && g.Dot(i)
+		switch {
+		case !open && broken:
+			ret[i].fill = t.WallError
+		case !open:
+			ret[i].fill = t.Wall
+		case broken:
+			ret[i].fill = t.Error
+		case o.Solution != nil && o.Solution[i]:
+			ret[i].fill = t.Solution
+		default:
+			ret[i].fill = t.Tile
+		}
+	}
+	return ret
+}
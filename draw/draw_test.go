@@ -0,0 +1,209 @@
+package draw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ostlerc/nurikabe/grid"
+)
+
+func TestTiles(t *testing.T) {
+	g, err := grid.FromText(strings.NewReader("2 # #\no # #\n. . .\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	theme := Themes[0]
+	ts := tiles(g, &Options{Violations: true, Solution: []bool{false, true, true, false, true, true, true, false, true}})
+	for i, want := range []string{theme.Tile, theme.WallError, theme.WallError, theme.Tile, theme.WallError, theme.WallError,
+		theme.Solution, theme.Tile, theme.Solution} {
+		if ts[i].fill != want {
+			t.Fatal("Invalid fill at", i, ts[i].fill)
+		}
+	}
+	if ts[0].count != 2 || !ts[3].dot || ts[6].dot {
+		t.Fatal("Invalid clues or dots")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	g, err := grid.FromText(strings.NewReader("1 # 12\no # .\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dark, ok := ThemeByName("dark")
+	if !ok {
+		t.Fatal("Missing dark theme")
+	}
+	if _, ok := ThemeByName("missing"); ok {
+		t.Fatal("Unknown theme found")
+	}
+	var b bytes.Buffer
+	if err := SVG(&b, g, Options{Cell: 20, Theme: dark}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `width="62" height="42"`) || !strings.Contains(b.String(), dark.Wall) {
+		t.Fatal("Invalid svg size or theme", b.String())
+	}
+
+	count := make(map[string]int)
+	var text []string
+	d := xml.NewDecoder(&b)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("Invalid svg", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			count[tok.Name.Local]++
+		case xml.CharData:
+			if s := strings.TrimSpace(string(tok)); s != "" {
+				text = append(text, s)
+			}
+		}
+	}
+	if count["svg"] != 1 || count["rect"] != 6 || count["circle"] != 1 || strings.Join(text, " ") != "1 12" {
+		t.Fatal("Invalid svg elements", count, text)
+	}
+}
+
+func TestImage(t *testing.T) {
+	g, err := grid.FromText(strings.NewReader("1 #\no 12\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := Image(g, Options{Cell: 20})
+	if b := img.Bounds(); b.Dx() != 42 || b.Dy() != 42 {
+		t.Fatal("Invalid image size", b)
+	}
+	theme := Themes[0]
+	if img.At(0, 0) != parseColor(theme.Border) || img.At(25, 5) != parseColor(theme.Wall) || img.At(5, 25) != parseColor(theme.Tile) {
+		t.Fatal("Invalid tile colors")
+	}
+	if img.At(11, 31) != parseColor(theme.TileText) {
+		t.Fatal("Missing dot")
+	}
+	for _, tl := range []struct{ x, y int }{{1, 1}, {21, 21}} {
+		text := 0
+		for y := tl.y; y < tl.y+20; y++ {
+			for x := tl.x; x < tl.x+20; x++ {
+				if img.At(x, y) == parseColor(theme.TileText) {
+					text++
+				}
+			}
+		}
+		if text == 0 {
+			t.Fatal("Missing clue at", tl)
+		}
+	}
+	if c := parseColor("#0a0b0c"); c != (color.RGBA{10, 11, 12, 255}) {
+		t.Fatal("Invalid color", c)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "draw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	level := filepath.Join(dir, "1.json")
+	c := &Cache{Dir: filepath.Join(dir, "cache"), Options: Options{Cell: 10}}
+
+	for i, text := range []string{
+		`{"rows":2,"cols":2,"tiles":[{"count":1}]}`,
+		`{"rows":2,"cols":2,"tiles":[{"count":1}]}`,
+		`{"rows":2,"cols":3,"tiles":[{"count":2}]}`,
+	} {
+		if err := ioutil.WriteFile(level, []byte(text), 0600); err != nil {
+			t.Fatal(err)
+		}
+		img, err := c.Image(level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds().Dx() != Image(mustJson(t, text), c.Options).Bounds().Dx() {
+			t.Fatal("Invalid cached image", i)
+		}
+		cached, _ := ioutil.ReadDir(c.Dir)
+		if want := []int{1, 1, 2}[i]; len(cached) != want {
+			t.Fatal("Cache has", len(cached), "images, expected", want)
+		}
+	}
+}
+
+func mustJson(t *testing.T, text string) *grid.Grid {
+	g, err := grid.FromJson(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestBooklet(t *testing.T) {
+	g := mustJson(t, `{"rows":2,"cols":2,"tiles":[{"count":2},{"count":1,"index":3}]}`)
+	puzzles := []Puzzle{
+		{Title: "easy (1)", Grid: g, Solution: []bool{false, true, false, false}},
+		{Title: "easy 2", Grid: g},
+		{Title: "easy 3", Grid: g, Solution: []bool{false, true, false, false}},
+	}
+	var b bytes.Buffer
+	if err := Booklet(&b, "Test", puzzles, 2); err != nil {
+		t.Fatal(err)
+	}
+	pdf := b.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("Invalid pdf header or trailer")
+	}
+	// 2 puzzle pages and 1 answer page
+	if !strings.Contains(pdf, "/Count 3 ") || !strings.Contains(pdf, "(Test - Answers)") || !strings.Contains(pdf, `(easy \(1\))`) {
+		t.Fatal("Invalid pages")
+	}
+
+	// titles are drawn in the text color, not the fill of the tile before them
+	text, fill := pdfColor(Themes[0].Text), ""
+	for _, line := range strings.Split(pdf, "\n") {
+		if i := strings.Index(line, " rg"); i >= 0 {
+			fill = line[:i]
+		}
+		if strings.Contains(line, "(easy") || strings.Contains(line, "(Test") {
+			if fill != text {
+				t.Fatal("Invalid title color", fill, line)
+			}
+		}
+	}
+
+	// every object of the xref table must be where it says
+	start := strings.LastIndex(pdf, "startxref\n")
+	var xref int
+	fmt.Sscanf(pdf[start+len("startxref\n"):], "%d", &xref)
+	if !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatal("Invalid startxref", xref)
+	}
+	lines := strings.Split(pdf[xref:], "\n")
+	var count int
+	fmt.Sscanf(lines[1], "0 %d", &count)
+	for i := 1; i < count; i++ {
+		var off int
+		fmt.Sscanf(lines[2+i], "%d", &off)
+		if !strings.HasPrefix(pdf[off:], strconv.Itoa(i)+" 0 obj\n") {
+			t.Fatal("Invalid offset of object", i)
+		}
+	}
+
+	if err := Booklet(&b, "Test", nil, 2); err == nil {
+		t.Fatal("Empty booklet written")
+	}
+}
@@ -0,0 +1,182 @@
+package draw
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/ostlerc/nurikabe/grid"
+)
+
+// Booklet pages are A4, measured in points.
+const (
+	pageWidth   = 595
+	pageHeight  = 842
+	pageMargin  = 40
+	headerSize  = 16
+	titleSize   = 11
+	digitWidth  = 0.556 // width of every Helvetica digit, in ems
+	borderWidth = 0.5
+)
+
+// Puzzle is a level of a booklet.
+type Puzzle struct {
+	Title    string
+	Grid     *grid.Grid
+	Solution []bool // closed tiles of the solution, nil to leave it out of the answers
+}
+
+// Booklet writes puzzles as a printable pdf, perPage puzzles to a page, then
+// the solutions in the same order under an answers heading. Only the fonts
+// every pdf reader has are used, so nothing is embedded.
+func Booklet(w io.Writer, title string, puzzles []Puzzle, perPage int) error {
+	if len(puzzles) == 0 {
+		return errors.New("no puzzles")
+	}
+	if perPage < 1 {
+		perPage = 1
+	}
+	var answers []Puzzle
+	for _, p := range puzzles {
+		if p.Solution != nil {
+			answers = append(answers, p)
+		}
+	}
+
+	var pages [][]byte
+	o := Options{Theme: Themes[0]}
+	for start := 0; start < len(puzzles); start += perPage {
+		end := start + perPage
+		if end > len(puzzles) {
+			end = len(puzzles)
+		}
+		pages = append(pages, bookletPage(title, puzzles[start:end], perPage, len(pages)+1, o, false))
+	}
+	for start := 0; start < len(answers); start += perPage {
+		end := start + perPage
+		if end > len(answers) {
+			end = len(answers)
+		}
+		pages = append(pages, bookletPage(title+" - Answers", answers[start:end], perPage, len(pages)+1, o, true))
+	}
+	return writePDF(w, pages)
+}
+
+// bookletPage returns the content of a page with puzzles laid out in a grid
+// of perPage slots.
+func bookletPage(header string, puzzles []Puzzle, perPage, number int, o Options, answers bool) []byte {
+	var b bytes.Buffer
+	// tiles leave their fill as the color of text, so it is set before each
+	text := pdfColor(o.theme().Text) + " rg\n"
+	b.WriteString(text)
+	pdfText(&b, pageMargin, pageHeight-pageMargin-headerSize, headerSize, header)
+	num := strconv.Itoa(number)
+	pdfText(&b, pageWidth/2-float64(len(num))*digitWidth*titleSize/2, pageMargin/2, titleSize, num)
+
+	cols := int(math.Ceil(math.Sqrt(float64(perPage))))
+	rows := (perPage + cols - 1) / cols
+	top := float64(pageHeight - 2*pageMargin - headerSize)
+	slotW := float64(pageWidth-2*pageMargin) / float64(cols)
+	slotH := (top - pageMargin) / float64(rows)
+	for k, p := range puzzles {
+		x := pageMargin + float64(k%cols)*slotW
+		y := top - float64(k/cols)*slotH // top of the slot
+		b.WriteString(text)
+		pdfText(&b, x, y-titleSize, titleSize, p.Title)
+
+		g := p.Grid
+		boardW, boardH := slotW-20, slotH-2*titleSize-20
+		cell := math.Min(boardW/float64(g.Columns()), boardH/float64(g.Rows()))
+		left := x + (slotW-cell*float64(g.Columns()))/2
+		boardTop := y - 2*titleSize
+
+		po := o
+		if answers {
+			po.Solution = p.Solution
+		}
+		fmt.Fprintf(&b, "%.2f w %s RG\n", borderWidth, pdfColor(po.theme().Border))
+		sz := g.Size()
+		for i, tl := range tiles(g, &po) {
+			c := sz.Cell(i)
+			tx := left + float64(c.Col)*cell
+			ty := boardTop - float64(c.Row+1)*cell
+			fmt.Fprintf(&b, "%s rg %.2f %.2f %.2f %.2f re B\n", pdfColor(tl.fill), tx, ty, cell, cell)
+			if tl.count > 0 {
+				s := strconv.Itoa(tl.count)
+				size := cell / 2
+				if width := float64(len(s)) * digitWidth * size; width > cell*0.8 {
+					size *= cell * 0.8 / width
+				}
+				fmt.Fprintf(&b, "%s rg\n", pdfColor(po.theme().TileText))
+				pdfText(&b, tx+cell/2-float64(len(s))*digitWidth*size/2, ty+cell/2-0.36*size, size, s)
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+// pdfText writes s at x, y in Helvetica. Characters pdf strings can not hold
+// as they are are replaced.
+func pdfText(b *bytes.Buffer, x, y, size float64, s string) {
+	var esc bytes.Buffer
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			esc.WriteByte('\\')
+			esc.WriteRune(r)
+		case r < ' ' || r > '~':
+			esc.WriteByte('?')
+		default:
+			esc.WriteRune(r)
+		}
+	}
+	fmt.Fprintf(b, "BT /F1 %.2f Tf %.2f %.2f Td (%s) Tj ET\n", size, x, y, esc.String())
+}
+
+// pdfColor returns a theme color as pdf color components.
+func pdfColor(s string) string {
+	c := parseColor(s)
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// writePDF writes a pdf document with a page for each content stream.
+func writePDF(w io.Writer, pages [][]byte) error {
+	b := bufio.NewWriter(w)
+	var offsets []int
+	written := 0
+	object := func(format string, args ...interface{}) {
+		offsets = append(offsets, written)
+		n, _ := fmt.Fprintf(b, "%d 0 obj\n", len(offsets))
+		written += n
+		n, _ = fmt.Fprintf(b, format, args...)
+		written += n
+		n, _ = b.WriteString("\nendobj\n")
+		written += n
+	}
+
+	n, _ := b.WriteString("%PDF-1.4\n")
+	written += n
+	var kids bytes.Buffer
+	for i := range pages {
+		fmt.Fprintf(&kids, "%d 0 R ", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [ %s] /Count %d >>", kids.String(), len(pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	for i, content := range pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 5+2*i)
+		object("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+
+	fmt.Fprintf(b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, written)
+	return b.Flush()
+}
@@ -0,0 +1,107 @@
+package draw
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+
+	"github.com/ostlerc/nurikabe/grid"
+)
+
+// digits is a 3x5 pixel font for clues, a row of three bits per line with
+// the left pixel in the high bit.
+var digits = [10][5]uint8{
+	{7, 5, 5, 5, 7},
+	{2, 6, 2, 2, 7},
+	{7, 1, 7, 4, 7},
+	{7, 1, 7, 1, 7},
+	{5, 5, 7, 1, 1},
+	{7, 4, 7, 1, 7},
+	{7, 4, 7, 5, 7},
+	{7, 1, 1, 1, 1},
+	{7, 5, 7, 5, 7},
+	{7, 5, 7, 1, 7},
+}
+
+// Image draws g like SVG, as a bitmap.
+func Image(g *grid.Grid, o Options) *image.RGBA {
+	cell, t := o.cell(), o.theme()
+	stroke := o.stroke()
+	img := image.NewRGBA(image.Rect(0, 0, g.Columns()*cell+2*stroke, g.Rows()*cell+2*stroke))
+	fill(img, img.Bounds(), parseColor(t.Border))
+
+	text, sz := parseColor(t.TileText), g.Size()
+	for i, tl := range tiles(g, &o) {
+		c := sz.Cell(i)
+		x, y := stroke+c.Col*cell, stroke+c.Row*cell
+		fill(img, image.Rect(x+stroke/2, y+stroke/2, x+cell-(stroke+1)/2, y+cell-(stroke+1)/2), parseColor(tl.fill))
+		cx, cy := x+cell/2, y+cell/2
+		switch {
+		case tl.count > 0:
+			drawNumber(img, tl.count, cx, cy, cell-2*stroke, text)
+		case tl.dot:
+			r := cell / 10
+			for dy := -r; dy <= r; dy++ {
+				for dx := -r; dx <= r; dx++ {
+					if dx*dx+dy*dy <= r*r {
+						img.Set(cx+dx, cy+dy, text)
+					}
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG writes g as a png image, see Image.
+func PNG(w io.Writer, g *grid.Grid, o Options) error {
+	return png.Encode(w, Image(g, o))
+}
+
+// drawNumber draws n centered on x, y with the digits font, half as high as
+// the tile and no wider than width.
+func drawNumber(img *image.RGBA, n, x, y, width int, c color.Color) {
+	s := strconv.Itoa(n)
+	scale := width / 8
+	for scale > 1 && len(s)*4*scale-scale > width {
+		scale--
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	left, top := x-(len(s)*4*scale-scale)/2, y-5*scale/2
+	for k, ch := range s {
+		for row, bits := range digits[ch-'0'] {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>uint(col)) != 0 {
+					px, py := left+(k*4+col)*scale, top+row*scale
+					fill(img, image.Rect(px, py, px+scale, py+scale), c)
+				}
+			}
+		}
+	}
+}
+
+// fill sets every pixel of r in img to c.
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// parseColor returns the color of a theme, black if it is not #rrggbb.
+func parseColor(s string) color.RGBA {
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{A: 0xff}
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{A: 0xff}
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
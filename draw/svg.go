@@ -0,0 +1,44 @@
+package draw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/ostlerc/nurikabe/grid"
+)
+
+// SVG writes g as an svg image, with o.Cell pixels per tile.
+func SVG(w io.Writer, g *grid.Grid, o Options) error {
+	cell, t := o.cell(), o.theme()
+	stroke := o.stroke()
+	width, height := g.Columns()*cell+2*stroke, g.Rows()*cell+2*stroke
+	ts, sz := tiles(g, &o), g.Size()
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(b, `<g stroke="%s" stroke-width="%d">`+"\n", t.Border, stroke)
+	for i, tl := range ts {
+		c := sz.Cell(i)
+		x, y := stroke+c.Col*cell, stroke+c.Row*cell
+		fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x, y, cell, cell, tl.fill)
+	}
+	fmt.Fprintln(b, `</g>`)
+
+	fmt.Fprintf(b, `<g fill="%s" font-family="sans-serif" font-size="%d" text-anchor="middle">`+"\n", t.TileText, cell/2)
+	for i, tl := range ts {
+		c := sz.Cell(i)
+		cx, cy := stroke+c.Col*cell+cell/2, stroke+c.Row*cell+cell/2
+		switch {
+		case tl.count > 0:
+			// the baseline is moved down a third of the font size to center digits
+			fmt.Fprintf(b, `<text x="%d" y="%d">%d</text>`+"\n", cx, cy+cell/6, tl.count)
+		case tl.dot:
+			fmt.Fprintf(b, `<circle cx="%d" cy="%d" r="%d"/>`+"\n", cx, cy, cell/10)
+		}
+	}
+	fmt.Fprintln(b, `</g>`)
+	fmt.Fprintln(b, `</svg>`)
+	return b.Flush()
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/pack"
+	"github.com/ostlerc/nurikabe/validator"
+
+	"gopkg.in/qml.v1"
+)
+
+const (
+	editorRows    = 5
+	editorCols    = 5
+	editorMinSize = 2
+	editorMaxSize = 15
+	newPack       = "+ New"
+)
+
+const (
+	actionAddRow    = "addrow"
+	actionRemoveRow = "removerow"
+	actionAddCol    = "addcol"
+	actionRemoveCol = "removecol"
+	actionSaveLevel = "savelevel"
+	actionURL       = "url"
+)
+
+func (w *window) buildEditor() {
+	if w.edit == nil {
+		w.edit = grid.New(editorRows, editorCols)
+	}
+	w.setStatus("Nurikabe - Editor " + strconv.Itoa(w.edit.Rows()) + "x" + strconv.Itoa(w.edit.Columns()))
+	w.qToolBtn().Set("text", "Menu")
+	w.buildTiles(w.edit)
+	for _, obj := range w.objs {
+		obj.Set("editing", true)
+	}
+
+	w.addAction("+Row", actionAddRow)
+	w.addAction("-Row", actionRemoveRow)
+	w.addAction("+Col", actionAddCol)
+	w.addAction("-Col", actionRemoveCol)
+	w.addAction("URL", actionURL)
+	w.addAction("Save", actionSaveLevel)
+	w.checkEdit()
+}
+
+func (w *window) buildPackSelect() {
+	w.setStatus("Nurikabe - Save To")
+	w.qGameGrid().Set("spacing", 15)
+	w.qGameGrid().Set("columns", 1)
+	w.qToolBtn().Set("text", "Back")
+
+	names := append(dirs(w.levelDir()), newPack)
+	w.objs = make([]qml.Object, len(names), len(names))
+	for i, name := range names {
+		w.objs[i] = w.btnComponent.Create(nil)
+		w.objs[i].Set("parent", w.qGameGrid())
+		w.objs[i].Set("text", name)
+		if name != newPack {
+			w.objs[i].Set("text", pack.Name(name))
+		}
+		w.objs[i].Set("data", name)
+		w.objs[i].Set("alignCenter", true)
+		w.objs[i].Set("width", w.winComponent.Root().Int("width")-150)
+	}
+}
+
+func (w *window) buildPackInput() {
+	w.clearGrid()
+	w.setStatus("Nurikabe - New Pack")
+
+	w.objs = make([]qml.Object, 1, 1)
+	w.objs[0] = w.inputComponent.Create(nil)
+	w.objs[0].Set("parent", w.qGameGrid())
+	w.objs[0].Set("placeholderText", "name")
+	w.objs[0].Set("width", w.winComponent.Root().Int("width")-150)
+	w.objs[0].Set("focus", true)
+	w.typing = true
+}
+
+// buildURLInput shows the puzz.link url of the edited grid, for copying or
+// pasting another url over it.
+func (w *window) buildURLInput() {
+	w.stopEditCheck()
+	w.generation++ // drop any check of the grid
+	w.clearGrid()
+	w.clearActions()
+	w.setStatus("Nurikabe - Paste URL")
+	w.qRecordText().Set("text", "")
+
+	url, _ := w.edit.URL()
+	w.objs = make([]qml.Object, 1, 1)
+	w.objs[0] = w.inputComponent.Create(nil)
+	w.objs[0].Set("parent", w.qGameGrid())
+	w.objs[0].Set("text", url)
+	w.objs[0].Set("width", w.winComponent.Root().Int("width")-50)
+	w.objs[0].Set("focus", true)
+	w.objs[0].Call("selectAll")
+	w.typing = true
+}
+
+// importURL replaces the edited grid with the clues of a puzz.link url.
+func (w *window) importURL(url string) {
+	g, err := grid.FromURL(url)
+	if err != nil {
+		w.setStatus("Nurikabe - Invalid URL")
+		return
+	}
+	if g.Rows() > editorMaxSize || g.Columns() > editorMaxSize {
+		w.setStatus("Nurikabe - Too large for the editor")
+		return
+	}
+	w.edit = g
+	w.setGameMode(editorPage)
+}
+
+// TileEdited changes the clue of tile i by delta, 0 removes the clue.
+func (w *window) TileEdited(i, delta int) {
+	c := w.edit.Count(i) + delta
+	if c < 0 || c > w.edit.Rows()*w.edit.Columns() {
+		return
+	}
+	w.edit.SetCount(i, c)
+	w.objs[i].Set("count", c)
+	w.checkEdit()
+}
+
+// resizeEdit changes the size of the edited grid, keeping the clues that
+// still fit.
+func (w *window) resizeEdit(rows, cols int) {
+	if rows < editorMinSize || cols < editorMinSize || rows > editorMaxSize || cols > editorMaxSize {
+		return
+	}
+	g := w.edit
+	if rows < g.Rows() {
+		g = g.Crop(0, 0, rows, g.Columns())
+	}
+	if cols < g.Columns() {
+		g = g.Crop(0, 0, g.Rows(), cols)
+	}
+	w.edit = g.Pad(0, 0, rows-g.Rows(), cols-g.Columns())
+	w.setGameMode(editorPage)
+}
+
+// transformEdit replaces the edited grid with the result of f, like a rotated
+// copy of it.
+func (w *window) transformEdit(f func(*grid.Grid) *grid.Grid) {
+	w.edit = f(w.edit)
+	w.setGameMode(editorPage)
+}
+
+// checkEdit solves a copy of the edited grid off the UI thread and shows the
+// first solution with its uniqueness and grade, unless the grid changed first.
+// The check of the previous grid is stopped, so only one runs at a time.
+func (w *window) checkEdit() {
+	w.stopEditCheck()
+	w.generation++
+	generation := w.generation
+	g := w.edit.Copy()
+	cancel := make(chan struct{})
+	w.editCancel = cancel
+	w.qRecordText().Set("text", "solving...")
+	go func() {
+		stop := func() bool {
+			select {
+			case <-cancel:
+				return true
+			default:
+				return false
+			}
+		}
+		solutions, grade, ok := validator.Check(g, validator.NewNurikabe(), 2, stop)
+		if !ok {
+			return
+		}
+		text := "no solution"
+		switch len(solutions) {
+		case 1:
+			text = "unique, " + grade.String()
+		case 2:
+			text = "not unique"
+		}
+		qml.Lock()
+		defer qml.Unlock()
+		if generation != w.generation || w.currentMode != editorPage {
+			return
+		}
+		w.qRecordText().Set("text", text)
+		for i, obj := range w.objs {
+			state := "open"
+			if len(solutions) > 0 && solutions[0][i] {
+				state = "closed"
+			}
+			obj.Set("state", state)
+		}
+	}()
+}
+
+// saveEdit adds the edited grid to the pack directory dir.
+func (w *window) saveEdit(dir string) {
+	name, err := saveLevel(w.edit, w.levelDir(), dir)
+	w.setGameMode(editorPage)
+	if err != nil {
+		w.setStatus("Nurikabe - Save failed")
+		return
+	}
+	w.setStatus("Nurikabe - Saved as " + pack.Name(dir) + " " + levelStr(name))
+}
+
+// stopEditCheck stops the running check of the edited grid, if any.
+func (w *window) stopEditCheck() {
+	if w.editCancel != nil {
+		close(w.editCancel)
+		w.editCancel = nil
+	}
+}
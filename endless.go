@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/pack"
+	"github.com/ostlerc/nurikabe/validator"
+
+	"gopkg.in/qml.v1"
+)
+
+const (
+	endlessDifficulty = "endless"
+	userPack          = "user" // name of the pack saved puzzles go to
+)
+
+type endlessLevel struct {
+	name       string
+	rows, cols int
+	minGardens int
+	gardenSize int
+	base       int
+}
+
+var endlessLevels = []*endlessLevel{
+	{"easy", 5, 5, 3, 3, 2},
+	{"medium", 6, 6, 4, 4, 2},
+	{"hard", 7, 7, 5, 4, 2},
+}
+
+// generate returns a new unsolved grid with a single solution.
+func (e *endlessLevel) generate() *grid.Grid {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	v := validator.NewNurikabe()
+	for {
+		g := grid.New(e.rows, e.cols)
+		g.GenerateRand(r, v, e.minGardens, e.gardenSize, e.base)
+		g.Clear()
+		if validator.Unique(g, v) {
+			return g
+		}
+	}
+}
+
+func (w *window) buildEndlessSelect() {
+	w.setStatus("Nurikabe - Endless")
+	w.qGameGrid().Set("spacing", 15)
+	w.qGameGrid().Set("columns", 1)
+	w.qToolBtn().Set("text", "Menu")
+
+	w.objs = make([]qml.Object, len(endlessLevels), len(endlessLevels))
+	for i, lvl := range endlessLevels {
+		w.objs[i] = w.btnComponent.Create(nil)
+		w.objs[i].Set("parent", w.qGameGrid())
+		w.objs[i].Set("text", lvl.name)
+		w.objs[i].Set("data", lvl.name)
+		w.objs[i].Set("alignCenter", true)
+		w.objs[i].Set("width", w.winComponent.Root().Int("width")-150)
+	}
+}
+
+func (w *window) buildGenerating() {
+	w.setStatus("Nurikabe - Generating " + w.currentBoard)
+	w.qGameGrid().Set("spacing", 15)
+	w.qGameGrid().Set("columns", 1)
+	w.qToolBtn().Set("text", "Back")
+
+	w.objs = make([]qml.Object, 1, 1)
+	w.objs[0] = w.busyComponent.Create(nil)
+	w.objs[0].Set("parent", w.qGameGrid())
+}
+
+// playEndless generates a puzzle for the current endless level off the UI
+// thread and starts it, unless the player left the generating page first.
+func (w *window) playEndless() {
+	var lvl *endlessLevel
+	for _, l := range endlessLevels {
+		if l.name == w.currentBoard {
+			lvl = l
+		}
+	}
+	w.generation++
+	generation := w.generation
+	w.setGameMode(generatingPage)
+	go func() {
+		g := lvl.generate()
+		qml.Lock()
+		defer qml.Unlock()
+		if generation != w.generation || w.currentMode != generatingPage {
+			return
+		}
+		w.g = g
+		w.startGame()
+	}()
+}
+
+func (w *window) endlessWon() {
+	w.setStatus("Nurikabe - Completed")
+	w.setTimer(false)
+	w.clearActions()
+	w.addAction("Next", actionNext)
+	w.addAction("Save", actionSave)
+}
+
+// saveEndless adds the current puzzle to the user pack.
+func (w *window) saveEndless() {
+	dir := w.userPackDir()
+	name, err := saveLevel(w.g, w.levelDir(), dir)
+	if err != nil {
+		w.setStatus("Nurikabe - Save failed")
+		return
+	}
+	w.setStatus("Nurikabe - Saved as " + pack.Name(dir) + " " + levelStr(name))
+	w.clearActions()
+	w.addAction("Next", actionNext)
+}
+
+// userPackDir returns the directory of the user pack, numbered after the other
+// packs when it does not exist yet.
+func (w *window) userPackDir() string {
+	packs := dirs(w.levelDir())
+	for _, p := range packs {
+		if pack.Name(p) == userPack {
+			return p
+		}
+	}
+	return pack.NewName(packs, userPack)
+}
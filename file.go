@@ -1,68 +1,38 @@
 package main
 
 import (
-	"io/ioutil"
-	"sort"
-	"strconv"
+	"fmt"
+	"os"
+
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/pack"
 )
 
+// dirs returns the packs in dir, or none if it can not be read.
 func dirs(dir string) []string {
-	files, err := ioutil.ReadDir(dir)
+	names, err := pack.Dirs(dir)
 	if err != nil {
-		panic(err)
-	}
-
-	l := len(files)
-	names := make([]string, 0, l)
-	for _, f := range files {
-		if f.IsDir() {
-			names = append(names, f.Name())
-		}
+		fmt.Fprintln(os.Stderr, "failed to read level packs", err)
 	}
-	sort.Strings(names)
 	return names
 }
 
-type numericint struct {
-	strs []string
-}
-
-// Len is part of sort.Interface.
-func (n *numericint) Len() int {
-	return len(n.strs)
-}
-
-// Swap is part of sort.Interface.
-func (n *numericint) Swap(i, j int) {
-	n.strs[i], n.strs[j] = n.strs[j], n.strs[i]
-}
-
-// Less is part of sort.Interface. It is implemented by calling the "by" closure in the sorter.
-func (n *numericint) Less(i, j int) bool {
-	iint, err := strconv.Atoi(n.strs[i][:len(n.strs[i])-5])
-	if err != nil {
-		panic(err)
-	}
-	jint, err := strconv.Atoi(n.strs[j][:len(n.strs[j])-5])
+// files returns the json levels of the pack dir in level order, or none if it
+// can not be read.
+func files(dir string) []string {
+	names, err := pack.Levels(dir)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, "failed to read levels", err)
 	}
-	return iint < jint
+	return names
 }
 
-func files(dir string) []string {
-	files, err := ioutil.ReadDir(dir)
+// saveLevel writes the clues of g as the next level of the pack dir in root,
+// creating the pack if needed. Returns the new level file name.
+func saveLevel(g *grid.Grid, root, dir string) (string, error) {
+	dat, err := g.Json()
 	if err != nil {
-		panic(err)
-	}
-
-	l := len(files)
-	names := make([]string, l, l)
-	for i, f := range files {
-		names[i] = f.Name()
+		return "", err
 	}
-
-	n := &numericint{strs: names}
-	sort.Sort(n)
-	return n.strs
+	return pack.Add(root+dir, append(dat, '\n'))
 }
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/pack"
 	"github.com/ostlerc/nurikabe/validator"
 )
 
@@ -16,13 +25,26 @@ var (
 	min    = flag.Int("min", 3, "minimum gardens count")
 	growth = flag.Int("growth", 4, "garden growth. base + growth is max garden size")
 	base   = flag.Int("base", 2, "minimum garden size")
+	count  = flag.Int("count", 1, "number of grids to generate")
 
-	verbose = flag.Bool("v", false, "Verbose")
-	debug   = flag.Bool("debug", false, "enable debug output")
-	solve   = flag.Bool("solve", false, "solve generated grid")
-	smart   = flag.Bool("smart", true, "solve using smart algorithm")
+	verbose   = flag.Bool("v", false, "Verbose")
+	debug     = flag.Bool("debug", false, "enable debug output")
+	solve     = flag.Bool("solve", false, "solve generated grid")
+	smart     = flag.Bool("smart", true, "solve using smart algorithm")
+	text      = flag.Bool("text", false, "read and write the text format, same as -from text -to text")
+	url       = flag.Bool("url", false, "read and write puzz.link urls, same as -from url -to url")
+	from      = flag.String("from", "auto", "input format: auto, "+strings.Join(grid.FormatNames(), ", "))
+	to        = flag.String("to", "json", "output format: "+strings.Join(grid.FormatNames(), ", "))
+	packDir   = flag.String("pack", "", "add the grids read to this level pack directory")
+	transform = flag.String("transform", "", "rotate, flip or transpose the grids, a comma separated list applied in order")
 )
 
+var transforms = map[string]func(*grid.Grid) *grid.Grid{
+	"rotate":    (*grid.Grid).Rotate,
+	"flip":      (*grid.Grid).Flip,
+	"transpose": (*grid.Grid).Transpose,
+}
+
 func init() {
 	flag.Parse()
 }
@@ -30,38 +52,147 @@ func init() {
 func main() {
 	validator.Verbose = *debug
 	v := validator.NewNurikabe()
-	var g *grid.Grid
-	var err error
+	var grids []*grid.Grid
+
+	switch {
+	case *text:
+		*from, *to = grid.FormatText.String(), grid.FormatText.String()
+	case *url:
+		*from, *to = grid.FormatURL.String(), grid.FormatURL.String()
+	}
+	out, ok := grid.FormatByName(*to)
+	if !ok {
+		log.Fatal("unknown format " + *to)
+	}
 
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) == 0 {
-		g, err = grid.FromJson(os.Stdin)
+		var err error
+		grids, err = read(os.Stdin, *from)
 		if err != nil {
 			log.Fatal(err)
 		}
 		if *verbose {
-			g.Print()
+			for _, g := range grids {
+				g.Print()
+			}
 		}
 	} else {
-		g = grid.New(*height, *width)
-		g.Generate(v, *min, *growth, *base)
-		if *verbose {
-			g.Print()
-			fmt.Println("")
+		for n := 0; n < *count; n++ {
+			g := grid.New(*height, *width)
+			g.Generate(v, *min, *growth, *base)
+			if *verbose {
+				g.Print()
+				fmt.Println("")
+			}
+			g.Clear()
+			grids = append(grids, g)
+		}
+	}
+
+	if *transform != "" {
+		for _, name := range strings.Split(*transform, ",") {
+			f, ok := transforms[strings.TrimSpace(name)]
+			if !ok {
+				log.Fatal("unknown transform " + name)
+			}
+			for i, g := range grids {
+				grids[i] = f(g)
+			}
 		}
 	}
 
+	if *packDir != "" {
+		for _, g := range grids {
+			name, err := savePack(g, *packDir)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(name)
+		}
+		return
+	}
+	if out == grid.FormatBinary {
+		// a single pack, without the solve output mixed in
+		for _, g := range grids {
+			if *solve {
+				g.Solve(v, *smart)
+				if !v.CheckWin(g) {
+					panic("Fail")
+				}
+			}
+		}
+		if err := grid.WritePack(os.Stdout, grids); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	for _, g := range grids {
+		write(v, g, out)
+	}
+}
+
+// write solves g if asked and prints it in format f.
+func write(v validator.GridValidator, g *grid.Grid, f grid.Format) {
 	if *solve {
 		g.Solve(v, *smart)
-		defer g.Print()
+		if !f.Walls() {
+			defer g.Print()
+		}
 		if !v.CheckWin(g) {
 			panic("Fail")
 		}
 		fmt.Println("solved")
 	}
-	j, err := g.Json()
+	if err := g.Write(os.Stdout, f); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// read reads the grids on input in the named format, detecting it for auto.
+// Binary packs and formats with a grid per line can hold many grids.
+func read(input io.Reader, name string) ([]*grid.Grid, error) {
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	f := grid.Detect(data)
+	if name != "auto" {
+		var ok bool
+		if f, ok = grid.FormatByName(name); !ok {
+			return nil, errors.New("unknown format " + name)
+		}
+	}
+	switch {
+	case f == grid.FormatBinary:
+		return grid.ReadPack(bytes.NewReader(data))
+	case !f.Lines():
+		g, err := grid.Read(bytes.NewReader(data), f)
+		return []*grid.Grid{g}, err
+	}
+
+	var grids []*grid.Grid
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; s.Scan(); line++ {
+		if strings.TrimSpace(s.Text()) == "" {
+			continue
+		}
+		g, err := grid.Read(strings.NewReader(s.Text()), f)
+		if err != nil {
+			return nil, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+		}
+		grids = append(grids, g)
+	}
+	return grids, s.Err()
+}
+
+// savePack writes g as the next numbered level of the pack in dir, creating
+// it if needed. Returns the new level file.
+func savePack(g *grid.Grid, dir string) (string, error) {
+	dat, err := g.Json()
 	if err != nil {
-		panic(err)
+		return "", err
 	}
-	fmt.Println(string(j))
+	name, err := pack.Add(dir, append(dat, '\n'))
+	return filepath.Join(dir, name), err
 }
@@ -0,0 +1,131 @@
+// Package geom finds the rows, columns and neighbors of the tiles of a grid.
+// Tiles are numbered row by row from the top left, so the tile at row r and
+// column c of a grid with cols columns is r*cols+c.
+package geom
+
+// Cell is the row and column of a tile.
+type Cell struct {
+	Row, Col int
+}
+
+// Add returns c moved by d rows and columns.
+func (c Cell) Add(d Cell) Cell {
+	return Cell{c.Row + d.Row, c.Col + d.Col}
+}
+
+// Size is the number of rows and columns of a grid.
+type Size struct {
+	Rows, Cols int
+}
+
+// Direction is a way to step from a tile to a 4-connected neighbor.
+type Direction int
+
+const (
+	Down Direction = iota
+	Up
+	Right
+	Left
+)
+
+// Directions holds every Direction in the order Neighbors visits them.
+var Directions = []Direction{Down, Up, Right, Left}
+
+// Len returns the number of tiles in a grid of size s.
+func (s Size) Len() int {
+	return s.Rows * s.Cols
+}
+
+// Contains returns true if c is inside a grid of size s.
+func (s Size) Contains(c Cell) bool {
+	return c.Row >= 0 && c.Row < s.Rows && c.Col >= 0 && c.Col < s.Cols
+}
+
+// Index returns the tile at c, or -1 if c is outside the grid.
+func (s Size) Index(c Cell) int {
+	if !s.Contains(c) {
+		return -1
+	}
+	return c.Row*s.Cols + c.Col
+}
+
+// Cell returns the row and column of tile i.
+func (s Size) Cell(i int) Cell {
+	return Cell{i / s.Cols, i % s.Cols}
+}
+
+// Step returns the tile next to i in direction d, and false if i is at that
+// edge of the grid.
+func (s Size) Step(i int, d Direction) (int, bool) {
+	switch d {
+	case Down:
+		return i + s.Cols, i+s.Cols < s.Len()
+	case Up:
+		return i - s.Cols, i >= s.Cols
+	case Right:
+		return i + 1, i%s.Cols != s.Cols-1
+	case Left:
+		return i - 1, i%s.Cols != 0
+	}
+	return -1, false
+}
+
+// Neighbors calls f with each 4-connected neighbor of i, in the order of
+// Directions.
+func (s Size) Neighbors(i int, f func(int)) {
+	for _, d := range Directions {
+		if x, ok := s.Step(i, d); ok {
+			f(x)
+		}
+	}
+}
+
+// Block returns the 2x2 block of tiles with i at its top left, in row order,
+// and false if i is on the bottom row or right column.
+func (s Size) Block(i int) ([4]int, bool) {
+	if i+s.Cols >= s.Len() || i%s.Cols == s.Cols-1 {
+		return [4]int{}, false
+	}
+	return [4]int{i, i + 1, i + s.Cols, i + s.Cols + 1}, true
+}
+
+// Blocks calls f with every 2x2 block of tiles, see Block.
+func (s Size) Blocks(f func([4]int)) {
+	for i := 0; i < s.Len(); i++ {
+		if b, ok := s.Block(i); ok {
+			f(b)
+		}
+	}
+}
+
+// Region returns the tiles 4-connected to i through tiles where in is true,
+// starting with i, marking them in seen. Tiles already marked are left out,
+// so regions can be found one at a time with the same seen. A nil seen is
+// made for this call.
+func (s Size) Region(i int, in func(int) bool, seen []bool) []int {
+	if seen == nil {
+		seen = make([]bool, s.Len())
+	}
+	seen[i] = true
+	ret := []int{i}
+	for j := 0; j < len(ret); j++ {
+		s.Neighbors(ret[j], func(x int) {
+			if !seen[x] && in(x) {
+				seen[x] = true
+				ret = append(ret, x)
+			}
+		})
+	}
+	return ret
+}
+
+// Regions calls f with each region of tiles alike by same, in the order of
+// their first tile.
+func (s Size) Regions(same func(a, b int) bool, f func([]int)) {
+	seen := make([]bool, s.Len())
+	for i := range seen {
+		if !seen[i] {
+			f(s.Region(i, func(x int) bool { return same(i, x) }, seen))
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+type cellTest struct {
+	size  Size
+	i     int
+	cell  Cell
+	valid bool
+}
+
+var cellTests = []*cellTest{
+	&cellTest{Size{3, 4}, 0, Cell{0, 0}, true},
+	&cellTest{Size{3, 4}, 3, Cell{0, 3}, true},
+	&cellTest{Size{3, 4}, 4, Cell{1, 0}, true},
+	&cellTest{Size{3, 4}, 11, Cell{2, 3}, true},
+	&cellTest{Size{1, 5}, 4, Cell{0, 4}, true},
+	&cellTest{Size{5, 1}, 4, Cell{4, 0}, true},
+	&cellTest{Size{3, 4}, -1, Cell{-1, 0}, false},
+	&cellTest{Size{3, 4}, -1, Cell{0, -1}, false},
+	&cellTest{Size{3, 4}, -1, Cell{3, 0}, false},
+	&cellTest{Size{3, 4}, -1, Cell{0, 4}, false},
+}
+
+func TestCell(t *testing.T) {
+	for _, c := range cellTests {
+		if got := c.size.Contains(c.cell); got != c.valid {
+			t.Fatal("Invalid contains", got, c)
+		}
+		if got := c.size.Index(c.cell); got != c.i {
+			t.Fatal("Invalid index", got, c)
+		}
+		if !c.valid {
+			continue
+		}
+		if got := c.size.Cell(c.i); got != c.cell {
+			t.Fatal("Invalid cell", got, c)
+		}
+	}
+
+	s := Size{4, 7}
+	if s.Len() != 28 {
+		t.Fatal("Invalid len", s.Len())
+	}
+	for i := 0; i < s.Len(); i++ {
+		if s.Index(s.Cell(i)) != i {
+			t.Fatal("Index does not undo cell", i)
+		}
+	}
+	if got := (Cell{1, 2}).Add(Cell{-1, 3}); got != (Cell{0, 5}) {
+		t.Fatal("Invalid add", got)
+	}
+}
+
+type neighborTest struct {
+	size Size
+	i    int
+	want []int
+}
+
+var neighborTests = []*neighborTest{
+	&neighborTest{Size{3, 3}, 4, []int{7, 1, 5, 3}},
+	&neighborTest{Size{3, 3}, 0, []int{3, 1}},
+	&neighborTest{Size{3, 3}, 2, []int{5, 1}},
+	&neighborTest{Size{3, 3}, 6, []int{3, 7}},
+	&neighborTest{Size{3, 3}, 8, []int{5, 7}},
+	&neighborTest{Size{3, 3}, 3, []int{6, 0, 4}},
+	&neighborTest{Size{3, 3}, 5, []int{8, 2, 4}},
+	&neighborTest{Size{1, 3}, 1, []int{2, 0}},
+	&neighborTest{Size{3, 1}, 1, []int{2, 0}},
+	&neighborTest{Size{1, 1}, 0, nil},
+}
+
+func TestNeighbors(t *testing.T) {
+	for _, n := range neighborTests {
+		var got []int
+		n.size.Neighbors(n.i, func(x int) { got = append(got, x) })
+		if !reflect.DeepEqual(got, n.want) {
+			t.Fatal("Invalid neighbors", got, n)
+		}
+	}
+
+	// every step agrees with moving the row or column by one
+	moves := map[Direction]Cell{Down: {1, 0}, Up: {-1, 0}, Right: {0, 1}, Left: {0, -1}}
+	s := Size{4, 5}
+	for i := 0; i < s.Len(); i++ {
+		for _, d := range Directions {
+			c := s.Cell(i).Add(moves[d])
+			x, ok := s.Step(i, d)
+			if ok != s.Contains(c) || ok && x != s.Index(c) {
+				t.Fatal("Invalid step", i, d, x, ok)
+			}
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	s := Size{3, 3}
+	if b, ok := s.Block(4); !ok || b != [4]int{4, 5, 7, 8} {
+		t.Fatal("Invalid block", b, ok)
+	}
+	for _, i := range []int{2, 5, 6, 7, 8} {
+		if _, ok := s.Block(i); ok {
+			t.Fatal("Block past the edge", i)
+		}
+	}
+
+	var got [][4]int
+	s.Blocks(func(b [4]int) { got = append(got, b) })
+	want := [][4]int{{0, 1, 3, 4}, {1, 2, 4, 5}, {3, 4, 6, 7}, {4, 5, 7, 8}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("Invalid blocks", got)
+	}
+
+	for _, s := range []Size{{1, 5}, {5, 1}, {1, 1}} {
+		s.Blocks(func(b [4]int) { t.Fatal("Block in a single line", s, b) })
+	}
+}
+
+func TestRegions(t *testing.T) {
+	// 1 1 0 1
+	// 0 1 0 1
+	// 1 0 0 1
+	s := Size{3, 4}
+	on := []bool{true, true, false, true, false, true, false, true, true, false, false, true}
+
+	got := s.Region(0, func(x int) bool { return on[x] }, nil)
+	if !reflect.DeepEqual(got, []int{0, 1, 5}) {
+		t.Fatal("Invalid region", got)
+	}
+
+	seen := make([]bool, s.Len())
+	s.Region(3, func(x int) bool { return on[x] }, seen)
+	if !seen[3] || !seen[7] || !seen[11] || seen[0] {
+		t.Fatal("Invalid seen", seen)
+	}
+	if got := s.Region(7, func(x int) bool { return on[x] && !seen[x] }, seen); len(got) != 1 {
+		t.Fatal("Region went through seen tiles", got)
+	}
+
+	var regions [][]int
+	s.Regions(func(a, b int) bool { return on[a] == on[b] }, func(r []int) { regions = append(regions, r) })
+	want := [][]int{{0, 1, 5}, {2, 6, 10, 9}, {3, 7, 11}, {4}, {8}}
+	if !reflect.DeepEqual(regions, want) {
+		t.Fatal("Invalid regions", regions)
+	}
+}
@@ -0,0 +1,241 @@
+package grid
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Binary format: a version byte, rows and columns, the number of clues, then
+// for each clue the tiles skipped since the previous clue and its value, all
+// as uvarints. A flags byte follows, with flagWalls set when a bitset of the
+// closed tiles ends the grid, lowest bit first.
+const (
+	binaryVersion = 1
+	flagWalls     = 1
+	maxTiles      = 1 << 20
+)
+
+// packMagic starts a pack of grids, see WritePack.
+const packMagic = "NKP\x01"
+
+// MarshalBinary returns g in the binary format. Dots are not kept.
+func (g *Grid) MarshalBinary() ([]byte, error) {
+	b := []byte{binaryVersion}
+	b = appendUvarint(b, g.rows)
+	b = appendUvarint(b, g.cols)
+	clues, walls := 0, false
+	for _, t := range g.tiles {
+		if t.count > 0 {
+			clues++
+		}
+		walls = walls || !t.open
+	}
+	b = appendUvarint(b, clues)
+	last := -1
+	for i, t := range g.tiles {
+		if t.count > 0 {
+			b = appendUvarint(b, i-last-1)
+			b = appendUvarint(b, t.count)
+			last = i
+		}
+	}
+	if !walls {
+		return append(b, 0), nil
+	}
+	b = append(b, flagWalls)
+	bits := make([]byte, (len(g.tiles)+7)/8)
+	for i, t := range g.tiles {
+		if !t.open {
+			bits[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return append(b, bits...), nil
+}
+
+// UnmarshalBinary sets g to the grid in data, see MarshalBinary.
+func (g *Grid) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryVersion {
+		return errors.New("unknown binary grid version")
+	}
+	r := &uvarintReader{data: data, pos: 1}
+	rows, cols, clues := r.next(), r.next(), r.next()
+	if r.err != nil {
+		return r.err
+	}
+	if rows < 1 || cols < 1 || rows > maxTiles/cols || clues > rows*cols {
+		return errors.New("invalid binary grid size " + strconv.Itoa(rows) + "x" + strconv.Itoa(cols))
+	}
+	*g = *New(rows, cols)
+	t := -1
+	for c := 0; c < clues; c++ {
+		t += r.next() + 1
+		count := r.next()
+		if r.err != nil {
+			return r.err
+		}
+		if t >= len(g.tiles) || count < 1 {
+			return errors.New("invalid clue " + strconv.Itoa(c+1) + " in binary grid")
+		}
+		g.tiles[t].count = count
+	}
+	if r.pos >= len(data) {
+		return errors.New("binary grid ends early")
+	}
+	flags := data[r.pos]
+	rest := data[r.pos+1:]
+	switch {
+	case flags == 0 && len(rest) == 0:
+		return nil
+	case flags == flagWalls && len(rest) == (len(g.tiles)+7)/8:
+		for i, tl := range g.tiles {
+			tl.open = rest[i/8]&(1<<uint(i%8)) == 0
+		}
+		return nil
+	}
+	return errors.New("invalid walls in binary grid")
+}
+
+// Compact returns g in the binary format as a short string, safe in urls and
+// file names. Compact strings always start with 'A'.
+func (g *Grid) Compact() string {
+	b, _ := g.MarshalBinary()
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// FromCompact reads a grid written by Compact.
+func FromCompact(s string) (*Grid, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New("invalid compact grid: " + err.Error())
+	}
+	g := &Grid{}
+	if err := g.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// PackWriter writes a pack of grids in the binary format, each after its
+// length, for collections too large for json.
+type PackWriter struct {
+	w *bufio.Writer
+}
+
+// NewPackWriter starts a pack on w, buffered until Flush.
+func NewPackWriter(w io.Writer) *PackWriter {
+	b := bufio.NewWriter(w)
+	b.WriteString(packMagic) // errors are kept by b
+	return &PackWriter{w: b}
+}
+
+// Write adds g to the pack.
+func (p *PackWriter) Write(g *Grid) error {
+	b, err := g.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if _, err := p.w.Write(appendUvarint(nil, len(b))); err != nil {
+		return err
+	}
+	_, err = p.w.Write(b)
+	return err
+}
+
+// Flush writes any buffered grids.
+func (p *PackWriter) Flush() error {
+	return p.w.Flush()
+}
+
+// PackReader reads the grids of a pack one at a time.
+type PackReader struct {
+	r       *bufio.Reader
+	started bool
+}
+
+func NewPackReader(r io.Reader) *PackReader {
+	return &PackReader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next grid of the pack, or io.EOF after the last one.
+func (p *PackReader) Read() (*Grid, error) {
+	if !p.started {
+		p.started = true
+		magic := make([]byte, len(packMagic))
+		if _, err := io.ReadFull(p.r, magic); err != nil || string(magic) != packMagic {
+			return nil, errors.New("not a grid pack")
+		}
+	}
+	n, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return nil, err // io.EOF at the end of the pack
+	}
+	if n > maxTiles {
+		return nil, errors.New("invalid grid length in pack")
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(p.r, b); err != nil {
+		return nil, errors.New("pack ends inside a grid")
+	}
+	g := &Grid{}
+	if err := g.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ReadPack returns all grids of a pack.
+func ReadPack(r io.Reader) ([]*Grid, error) {
+	p := NewPackReader(r)
+	var grids []*Grid
+	for {
+		g, err := p.Read()
+		if err == io.EOF {
+			return grids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		grids = append(grids, g)
+	}
+}
+
+// WritePack writes grids as a pack.
+func WritePack(w io.Writer, grids []*Grid) error {
+	p := NewPackWriter(w)
+	for _, g := range grids {
+		if err := p.Write(g); err != nil {
+			return err
+		}
+	}
+	return p.Flush()
+}
+
+func appendUvarint(b []byte, v int) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], uint64(v))]...)
+}
+
+// uvarintReader reads uvarints from data, keeping the first error.
+type uvarintReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *uvarintReader) next() int {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 || v > maxTiles {
+		r.err = errors.New("invalid number in binary grid")
+		return 0
+	}
+	r.pos += n
+	return int(v)
+}
@@ -0,0 +1,32 @@
+package grid
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+)
+
+// Canonical returns the clues of g in the same position for every rotation
+// and reflection of g, including those turning a grid on its side. Puzzles
+// are the same up to symmetry when their canonical grids are.
+func (g *Grid) Canonical() *Grid {
+	var best *Grid
+	var bestKey []byte
+	for s := 0; s < Symmetries; s++ {
+		t := g.Symmetry(s)
+		t.Clear()
+		key, _ := t.MarshalBinary()
+		if best == nil || bytes.Compare(key, bestKey) < 0 {
+			best, bestKey = t, key
+		}
+	}
+	return best
+}
+
+// Hash returns a hex string naming the puzzle of g, the same for every
+// rotation and reflection of it. Walls and dots are left out.
+func (g *Grid) Hash() string {
+	key, _ := g.Canonical().MarshalBinary()
+	sum := sha1.Sum(key)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,27 @@
+package grid
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/ostlerc/nurikabe/validator"
+)
+
+// daily puzzle parameters, changing these changes every daily puzzle
+const (
+	dailyRows    = 7
+	dailyCols    = 7
+	dailyGardens = 5
+	dailyGrowth  = 4
+	dailyBase    = 2
+)
+
+// Daily generates the unsolved puzzle for the calendar day of t. Every call
+// for the same day returns the same puzzle.
+func Daily(v validator.GridValidator, t time.Time) *Grid {
+	y, m, d := t.Date()
+	g := New(dailyRows, dailyCols)
+	g.GenerateRand(rand.New(rand.NewSource(int64(y*10000+int(m)*100+d))), v, dailyGardens, dailyGrowth, dailyBase)
+	g.Clear()
+	return g
+}
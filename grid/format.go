@@ -0,0 +1,164 @@
+package grid
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// Format is a way of writing a grid down, see Read and Write.
+type Format int
+
+const (
+	FormatJson    Format = iota // our level files
+	FormatText                  // see FromText
+	FormatURL                   // see FromURL
+	FormatPzpr                  // see FromPzpr
+	FormatJanko                 // see FromJanko
+	FormatGameID                // see FromGameID
+	FormatCompact               // see FromCompact
+	FormatBinary                // see ReadPack
+)
+
+var formatNames = []string{"json", "text", "url", "pzprv3", "janko", "gameid", "compact", "binary"}
+
+var (
+	gameIDPattern  = regexp.MustCompile(`^\d+x\d+:`)
+	compactPattern = regexp.MustCompile(`^A[A-Za-z0-9_-]*$`)
+)
+
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// Walls returns true if f keeps the walls of a grid, not only its clues.
+func (f Format) Walls() bool {
+	return f != FormatJson && f != FormatURL && f != FormatGameID
+}
+
+// Lines returns true if f holds a grid on every line, like a list of urls.
+func (f Format) Lines() bool {
+	return f == FormatURL || f == FormatGameID || f == FormatCompact
+}
+
+// FormatByName returns the format called name.
+func FormatByName(name string) (Format, bool) {
+	for i, n := range formatNames {
+		if n == name {
+			return Format(i), true
+		}
+	}
+	return FormatJson, false
+}
+
+// FormatNames returns the names of all formats.
+func FormatNames() []string {
+	return append([]string(nil), formatNames...)
+}
+
+// Detect guesses the format of data from its first line.
+func Detect(data []byte) Format {
+	if bytes.HasPrefix(data, []byte(packMagic)) {
+		return FormatBinary
+	}
+	line := strings.TrimSpace(string(data))
+	if n := strings.Index(line, "\n"); n != -1 {
+		line = strings.TrimSpace(line[:n])
+	}
+	switch {
+	case strings.HasPrefix(line, "{"):
+		return FormatJson
+	case strings.HasPrefix(line, "pzprv3"):
+		return FormatPzpr
+	case strings.HasPrefix(line, "["):
+		return FormatJanko
+	case strings.Contains(line, "?nurikabe/"):
+		return FormatURL
+	case gameIDPattern.MatchString(line):
+		return FormatGameID
+	case compactPattern.MatchString(line):
+		return FormatCompact
+	}
+	return FormatText
+}
+
+// Read reads a grid in format f. Formats holding several grids are read up
+// to the first one.
+func Read(input io.Reader, f Format) (*Grid, error) {
+	switch f {
+	case FormatJson:
+		return FromJson(input)
+	case FormatText:
+		return FromText(input)
+	case FormatPzpr:
+		return FromPzpr(input)
+	case FormatJanko:
+		return FromJanko(input)
+	case FormatBinary:
+		return NewPackReader(input).Read()
+	}
+	s := bufio.NewScanner(input)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			switch f {
+			case FormatURL:
+				return FromURL(line)
+			case FormatCompact:
+				return FromCompact(line)
+			}
+			return FromGameID(line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("empty " + f.String())
+}
+
+// ReadAny reads a grid in the format given by Detect, which it returns.
+func ReadAny(input io.Reader) (*Grid, Format, error) {
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, FormatJson, err
+	}
+	f := Detect(data)
+	g, err := Read(bytes.NewReader(data), f)
+	return g, f, err
+}
+
+// Write writes g in format f, ending with a new line except for a binary
+// pack of just g.
+func (g *Grid) Write(w io.Writer, f Format) error {
+	var line string
+	switch f {
+	case FormatText:
+		return g.WriteText(w)
+	case FormatPzpr:
+		return g.WritePzpr(w)
+	case FormatJanko:
+		return g.WriteJanko(w)
+	case FormatJson:
+		j, err := g.Json()
+		if err != nil {
+			return err
+		}
+		line = string(j)
+	case FormatURL:
+		var err error
+		if line, err = g.URL(); err != nil {
+			return err
+		}
+	case FormatGameID:
+		line = g.GameID()
+	case FormatCompact:
+		line = g.Compact()
+	case FormatBinary:
+		return WritePack(w, []*Grid{g})
+	}
+	_, err := io.WriteString(w, line+"\n")
+	return err
+}
@@ -0,0 +1,92 @@
+package grid
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// FromGameID reads a grid from a game id in the style of Simon Tatham's
+// Puzzles, <columns>x<rows>:<clues>. Clues are written in order as numbers,
+// with letters 'a' to 'z' skipping 1 to 26 tiles without a clue and '_'
+// between clues that are next to each other.
+func FromGameID(id string) (*Grid, error) {
+	id = strings.TrimSpace(id)
+	colon := strings.Index(id, ":")
+	if colon == -1 {
+		return nil, errors.New("missing ':' in game id")
+	}
+	size := strings.SplitN(id[:colon], "x", 2)
+	if len(size) != 2 {
+		return nil, errors.New("invalid size " + strconv.Quote(id[:colon]))
+	}
+	cols, err := strconv.Atoi(size[0])
+	if err != nil || cols < 1 {
+		return nil, errors.New("invalid width " + strconv.Quote(size[0]))
+	}
+	rows, err := strconv.Atoi(size[1])
+	if err != nil || rows < 1 {
+		return nil, errors.New("invalid height " + strconv.Quote(size[1]))
+	}
+
+	g := New(rows, cols)
+	desc := id[colon+1:]
+	t := 0
+	for i := 0; i < len(desc); {
+		ch := desc[i]
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			t += int(ch-'a') + 1
+			i++
+		case ch == '_':
+			i++
+		case ch >= '0' && ch <= '9':
+			j := i
+			for j < len(desc) && desc[j] >= '0' && desc[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(desc[i:j])
+			if n < 1 || t >= len(g.tiles) {
+				return nil, errors.New("invalid clue " + strconv.Quote(desc[i:j]) + " at tile " + strconv.Itoa(t+1))
+			}
+			g.tiles[t].count = n
+			t++
+			i = j
+		default:
+			return nil, errors.New("invalid character " + strconv.Quote(string(ch)) + " in game id")
+		}
+	}
+	if t > len(g.tiles) {
+		return nil, errors.New("game id has " + strconv.Itoa(t) + " tiles, expected " + strconv.Itoa(len(g.tiles)))
+	}
+	return g, nil
+}
+
+// GameID returns the clues of g as a game id, see FromGameID.
+func (g *Grid) GameID() string {
+	var b bytes.Buffer
+	b.WriteString(strconv.Itoa(g.cols) + "x" + strconv.Itoa(g.rows) + ":")
+	skip := 0
+	for i, t := range g.tiles {
+		if t.count == 0 {
+			skip++
+			if skip == 26 {
+				b.WriteByte('z')
+				skip = 0
+			}
+			continue
+		}
+		if skip > 0 {
+			b.WriteByte(byte('a' + skip - 1))
+			skip = 0
+		} else if i > 0 && g.tiles[i-1].count > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteString(strconv.Itoa(t.count))
+	}
+	if skip > 0 {
+		b.WriteByte(byte('a' + skip - 1))
+	}
+	return b.String()
+}
@@ -1,6 +1,11 @@
 package grid
 
-import "github.com/ostlerc/nurikabe/validator"
+import (
+	"math/rand"
+
+	"github.com/ostlerc/nurikabe/geom"
+	"github.com/ostlerc/nurikabe/validator"
+)
 
 const (
 	opened = iota
@@ -10,6 +15,12 @@ const (
 
 //TODO: add difficulty parameter
 func (g *Grid) Generate(v validator.GridValidator, minGardens, gardenSize, base int) {
+	g.GenerateRand(R, v, minGardens, gardenSize, base)
+}
+
+// GenerateRand is Generate using r as the source of randomness, the same r
+// state always generates the same grid.
+func (g *Grid) GenerateRand(r *rand.Rand, v validator.GridValidator, minGardens, gardenSize, base int) {
 	tileMap := make(mapset, len(g.tiles))
 	for {
 		g.reset()
@@ -18,7 +29,7 @@ func (g *Grid) Generate(v validator.GridValidator, minGardens, gardenSize, base
 		}
 
 		c := 0
-		for ; g.placeGarden(base, R.Intn(gardenSize)+base, tileMap); c++ {
+		for ; g.placeGarden(r, base, r.Intn(gardenSize)+base, tileMap); c++ {
 		}
 
 		if c < minGardens {
@@ -35,18 +46,18 @@ func (g *Grid) Generate(v validator.GridValidator, minGardens, gardenSize, base
 	}
 }
 
-func (g *Grid) placeGarden(min, max int, tileMap mapset) bool {
+func (g *Grid) placeGarden(r *rand.Rand, min, max int, tileMap mapset) bool {
 	i := -1
 	for c := 0; c < 10; c++ {
-		z := R.Intn(len(tileMap))
+		z := r.Intn(len(tileMap))
 		if tileMap[z] == closed {
 			i = z
 			break
 		}
 	}
 	if i == -1 {
-		for k, v := range tileMap {
-			if v == closed {
+		for k := 0; k < len(tileMap); k++ {
+			if tileMap[k] == closed {
 				i = k
 				break
 			}
@@ -55,7 +66,7 @@ func (g *Grid) placeGarden(min, max int, tileMap mapset) bool {
 			return false
 		}
 	}
-	tiles := g.markOpen(i, max, tileMap)
+	tiles := g.markOpen(r, i, max, tileMap)
 	if len(tiles) < min {
 		return false
 	}
@@ -65,41 +76,37 @@ func (g *Grid) placeGarden(min, max int, tileMap mapset) bool {
 	return true
 }
 
-func (g *Grid) markOpen(i, c int, tileMap mapset) []int {
+func (g *Grid) markOpen(r *rand.Rand, i, c int, tileMap mapset) []int {
 	if c == 0 || tileMap[i] == sealed || tileMap[i] == opened {
 		return []int{}
 	}
-	steps := []int{1, -1, g.cols, -g.cols}
-
-	if i/g.cols == g.rows-1 { // bottom of grid
-		steps = remove(g.cols, steps)
-	}
-
-	if i < g.cols { // top of grid
-		steps = remove(-g.cols, steps)
-	}
-
-	if i%g.cols == g.cols-1 { // right side of grid
-		steps = remove(1, steps)
+	// directions past the edges are swapped out in the order random growth
+	// has always used, so seeded puzzles like the daily one stay the same
+	sz := g.Size()
+	dirs := []int{int(geom.Right), int(geom.Left), int(geom.Down), int(geom.Up)}
+	for _, d := range geom.Directions {
+		if _, ok := sz.Step(i, d); !ok {
+			dirs = remove(int(d), dirs)
+		}
 	}
-
-	if i%g.cols == 0 { // left side of grid
-		steps = remove(-1, steps)
+	near := make([]int, len(dirs))
+	for k, d := range dirs {
+		near[k], _ = sz.Step(i, geom.Direction(d))
 	}
 
-	remainingSteps := make([]int, len(steps))
-	copy(remainingSteps, steps)
+	remaining := make([]int, len(near))
+	copy(remaining, near)
 
 	ret := make([]int, 0, c)
 	ret = append(ret, i)
 	c--
 	tileMap[i] = opened
-	for c > 0 && len(remainingSteps) > 0 {
-		stepIndex := R.Intn(len(remainingSteps))
-		v := remainingSteps[stepIndex] + i
-		remainingSteps = removeAt(stepIndex, remainingSteps)
+	for c > 0 && len(remaining) > 0 {
+		k := r.Intn(len(remaining))
+		v := remaining[k]
+		remaining = removeAt(k, remaining)
 
-		tList := g.markOpen(v, c, tileMap)
+		tList := g.markOpen(r, v, c, tileMap)
 		if l := len(tList); l > 0 {
 			c -= l
 			ret = append(ret, tList...)
@@ -113,8 +120,8 @@ func (g *Grid) markOpen(i, c int, tileMap mapset) []int {
 	}
 
 	//seal up boundaries
-	for _, s := range steps {
-		seal(s + i)
+	for _, x := range near {
+		seal(x)
 	}
 	return ret
 }
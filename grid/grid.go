@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/ostlerc/nurikabe/geom"
 	"github.com/ostlerc/nurikabe/validator"
 )
 
@@ -11,6 +12,7 @@ var R = rand.New(rand.NewSource(time.Now().UnixNano()))
 
 type tile struct {
 	open  bool
+	dot   bool // marked by the player as part of an island
 	count int
 }
 
@@ -21,17 +23,56 @@ type Grid struct {
 }
 
 func (g *Grid) Toggle(i int) {
-	g.tiles[i].open = !g.tiles[i].open
+	g.SetOpen(i, !g.tiles[i].open)
 }
 
 func (g *Grid) Open(i int) bool {
 	return g.tiles[i].open
 }
 
+// SetOpen opens or closes tile i, removing its dot.
+func (g *Grid) SetOpen(i int, open bool) {
+	g.tiles[i].open = open
+	g.tiles[i].dot = false
+}
+
+// Dot returns true if the open tile i is marked as part of an island.
+func (g *Grid) Dot(i int) bool {
+	return g.tiles[i].dot
+}
+
+// SetDot marks or unmarks tile i as part of an island, opening it.
+func (g *Grid) SetDot(i int, dot bool) {
+	g.tiles[i].open = true
+	g.tiles[i].dot = dot
+}
+
 func (g *Grid) Count(i int) int {
 	return g.tiles[i].count
 }
 
+// SetCount sets the clue of tile i, 0 removes it.
+func (g *Grid) SetCount(i, c int) {
+	g.tiles[i].count = c
+}
+
+// Copy returns a grid with the same clues and tiles as g.
+func (g *Grid) Copy() *Grid {
+	c := New(g.rows, g.cols)
+	for i, t := range g.tiles {
+		*c.tiles[i] = *t
+	}
+	return c
+}
+
+// Clear opens every tile, leaving only the clues.
+func (g *Grid) Clear() {
+	for _, t := range g.tiles {
+		t.open = true
+		t.dot = false
+	}
+}
+
 func (g *Grid) Rows() int {
 	return g.rows
 }
@@ -40,6 +81,12 @@ func (g *Grid) Columns() int {
 	return g.cols
 }
 
+// Size returns the rows and columns of g, for finding the row, column and
+// neighbors of its tiles.
+func (g *Grid) Size() geom.Size {
+	return geom.Size{Rows: g.rows, Cols: g.cols}
+}
+
 func New(rows, cols int) *Grid {
 	size := rows * cols
 	g := &Grid{
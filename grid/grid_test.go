@@ -1,9 +1,11 @@
 package grid
 
 import (
+	"bytes"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ostlerc/nurikabe/validator"
 )
@@ -73,3 +75,292 @@ func TestBuildGrid(t *testing.T) {
 		t.Fatal("Invalid rows ", g.rows)
 	}
 }
+
+func TestDaily(t *testing.T) {
+	day := time.Date(2014, 8, 1, 10, 0, 0, 0, time.UTC)
+	a, _ := Daily(v, day).Json()
+	b, _ := Daily(v, day.Add(time.Hour)).Json()
+	if string(a) != string(b) {
+		t.Fatal("Daily puzzle changed during the day", string(a), string(b))
+	}
+	c, _ := Daily(v, day.AddDate(0, 0, 1)).Json()
+	if string(a) == string(c) {
+		t.Fatal("Daily puzzle repeated", string(a))
+	}
+}
+
+func TestDot(t *testing.T) {
+	g := New(2, 2)
+	g.Toggle(0)
+	g.SetDot(0, true)
+	if !g.Open(0) || !g.Dot(0) {
+		t.Fatal("Dot did not open tile")
+	}
+	g.Toggle(0)
+	if g.Open(0) || g.Dot(0) {
+		t.Fatal("Toggle kept dot")
+	}
+}
+
+func TestText(t *testing.T) {
+	text := " 2  .  #  .\n .  #  o 10\n"
+	g, err := FromText(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Rows() != 2 || g.Columns() != 4 || g.Count(0) != 2 || g.Count(7) != 10 ||
+		g.Open(2) || !g.Open(1) || !g.Dot(6) || g.Dot(1) {
+		t.Fatal("Invalid text grid")
+	}
+	var b bytes.Buffer
+	if err := g.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != text {
+		t.Fatal("Invalid text", b.String())
+	}
+
+	if g, err := FromText(strings.NewReader("2.#\n.#o\n")); err != nil || g.Columns() != 3 || g.Count(0) != 2 {
+		t.Fatal("Invalid compact grid", err)
+	}
+	if g, err := FromText(strings.NewReader("12\n3.\n")); err != nil || g.Columns() != 2 || g.Count(1) != 2 || g.Count(2) != 3 {
+		t.Fatal("Invalid compact grid with digits", err)
+	}
+
+	// one column grids keep clues with more than one digit
+	g = New(2, 1)
+	g.SetCount(0, 12)
+	b.Reset()
+	if err := g.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := FromText(&b); err != nil || r.Rows() != 2 || r.Columns() != 1 || r.Count(0) != 12 || r.Count(1) != 0 {
+		t.Fatal("Invalid one column grid", err)
+	}
+
+	for _, bad := range []string{"", "1 2\n3\n", "1 x\n", "0 .\n", "1 2\n345\n"} {
+		if _, err := FromText(strings.NewReader(bad)); err == nil {
+			t.Fatal("Invalid grid accepted", bad)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	g := New(2, 3)
+	g.SetCount(0, 16)
+	g.SetCount(5, 300)
+	url, err := g.URL()
+	if err != nil || url != URLPrefix+"3/2/-10j+12c" {
+		t.Fatal("Invalid url", url, err)
+	}
+	for _, u := range []string{url, "http://pzv.jp/p.html?nurikabe/3/2/-10j+12c", "https://puzz.link/p?nurikabe/v:/3/2/-10j+12c/"} {
+		g, err := FromURL(u)
+		if err != nil || g.Rows() != 2 || g.Columns() != 3 || g.Count(0) != 16 || g.Count(5) != 300 || g.Count(1) != 0 {
+			t.Fatal("Invalid url grid", u, err)
+		}
+	}
+
+	g = New(5, 5)
+	g.SetCount(0, 2)
+	g.SetCount(22, 1)
+	if url, _ := g.URL(); url != URLPrefix+"5/5/2zg1h" {
+		t.Fatal("Invalid url", url)
+	}
+	for _, bad := range []string{"", "https://puzz.link/p?lits/3/2/", "?nurikabe/3", "?nurikabe/0/2/", "?nurikabe/2/2/.", "?nurikabe/2/2/-1", "?nurikabe/2/2/0"} {
+		if _, err := FromURL(bad); err == nil {
+			t.Fatal("Invalid url accepted", bad)
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	g := New(2, 3)
+	g.SetCount(0, 16)
+	g.SetCount(1, 1)
+	g.SetOpen(3, false)
+	g.SetDot(4, true)
+	for _, name := range FormatNames() {
+		f, ok := FormatByName(name)
+		if !ok || f.String() != name {
+			t.Fatal("Invalid format name", name)
+		}
+		var b bytes.Buffer
+		if err := g.Write(&b, f); err != nil {
+			t.Fatal(err)
+		}
+		if d := Detect(b.Bytes()); d != f {
+			t.Fatal("Detected", d, "for", f, b.String())
+		}
+		r, detected, err := ReadAny(&b)
+		if err != nil || detected != f {
+			t.Fatal("Invalid read", f, err)
+		}
+		if r.Rows() != 2 || r.Columns() != 3 || r.Count(0) != 16 || r.Count(1) != 1 || r.Count(2) != 0 {
+			t.Fatal("Invalid clues", f)
+		}
+		if f.Walls() && (r.Open(3) || !r.Open(5)) {
+			t.Fatal("Invalid walls", f)
+		}
+		if (f == FormatText || f == FormatPzpr) && !r.Dot(4) {
+			t.Fatal("Invalid dots", f)
+		}
+	}
+
+	if id := g.GameID(); id != "3x2:16_1d" {
+		t.Fatal("Invalid game id", id)
+	}
+	if g, err := FromGameID("30x1:za1b"); err != nil || g.Count(27) != 1 {
+		t.Fatal("Invalid game id grid", err)
+	}
+	for _, bad := range []string{"3x2", "3:16", "3x2:16_1e", "3x2:0", "3x2:1?"} {
+		if _, err := FromGameID(bad); err == nil {
+			t.Fatal("Invalid game id accepted", bad)
+		}
+	}
+
+	pzpr := "pzprv3\nnurikabe\n2\n2\n1 # \n+ . \n"
+	if g, err := FromPzpr(strings.NewReader(pzpr)); err != nil || g.Count(0) != 1 || g.Open(1) || !g.Dot(2) {
+		t.Fatal("Invalid pzprv3 grid", err)
+	}
+	for _, bad := range []string{"", "pzprv3\nlits\n2\n2\n. .\n. .\n", "pzprv3\nnurikabe\n2\n2\n. .\n", "pzprv3\nnurikabe\n1\n2\n- .\n"} {
+		if _, err := FromPzpr(strings.NewReader(bad)); err == nil {
+			t.Fatal("Invalid pzprv3 accepted", bad)
+		}
+	}
+
+	janko := "[setup]\nauthor = someone\npuzzle = Nurikabe\nsize = 2\n[problem]\n1 -\n- 1\n[solution]\n1 x\nx 1\n[end]\n"
+	if g, err := FromJanko(strings.NewReader(janko)); err != nil || g.Count(3) != 1 || g.Open(1) || !g.Open(0) {
+		t.Fatal("Invalid janko grid", err)
+	}
+	for _, bad := range []string{"", "[setup]\npuzzle = Sudoku\n[problem]\n1\n", "[problem]\n1 -\n-\n", "[setup]\nrows = 3\n[problem]\n1 -\n- 1\n",
+		"[problem]\n1 -\n- 1\n[solution]\nx -\n- 1\n"} {
+		if _, err := FromJanko(strings.NewReader(bad)); err == nil {
+			t.Fatal("Invalid janko accepted", bad)
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	g := New(3, 4)
+	g.SetCount(0, 2)
+	g.SetCount(11, 200)
+	g.SetOpen(5, false)
+	c := g.Compact()
+	if c[0] != 'A' {
+		t.Fatal("Invalid compact grid", c)
+	}
+	r, err := FromCompact(c)
+	if err != nil || r.Rows() != 3 || r.Columns() != 4 || r.Count(0) != 2 || r.Count(11) != 200 || r.Open(5) || !r.Open(4) {
+		t.Fatal("Invalid compact round trip", c, err)
+	}
+	g.SetOpen(5, true)
+	if b, _ := g.MarshalBinary(); len(b) != 10 {
+		t.Fatal("Binary grid without walls has", len(b), "bytes")
+	}
+
+	var b bytes.Buffer
+	grids := []*Grid{g, New(1, 1), r}
+	if err := WritePack(&b, grids); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+	read, err := ReadPack(bytes.NewReader(data))
+	if err != nil || len(read) != len(grids) {
+		t.Fatal("Invalid pack", len(read), err)
+	}
+	for i := range grids {
+		if read[i].Compact() != grids[i].Compact() {
+			t.Fatal("Invalid pack grid", i)
+		}
+	}
+	if _, err := ReadPack(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Fatal("Truncated pack accepted")
+	}
+	if _, err := ReadPack(strings.NewReader("{}")); err == nil {
+		t.Fatal("Invalid pack accepted")
+	}
+
+	for _, bad := range []string{"", "B", "AQ", "AQIC", "AQICAQMA", "AQICAQEBAAA", "AQICAAE"} {
+		if _, err := FromCompact(bad); err == nil {
+			t.Fatal("Invalid compact grid accepted", bad)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	g, err := FromText(strings.NewReader("3 . .\n. # 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := g.Hash()
+	for s := 0; s < Symmetries; s++ {
+		m := g.Symmetry(s)
+		if s&4 != 0 && (m.Rows() != 3 || m.Columns() != 2) {
+			t.Fatal("Invalid symmetry size", s, m.Rows(), m.Columns())
+		}
+		if m.Hash() != hash || m.Canonical().Compact() != g.Canonical().Compact() {
+			t.Fatal("Hash changed by symmetry", s)
+		}
+	}
+	if m := g.Symmetry(5); m.Count(4) != 3 || m.Count(1) != 1 || m.Open(3) {
+		t.Fatal("Invalid symmetry")
+	}
+	if g.Symmetry(0).Compact() != g.Compact() {
+		t.Fatal("Symmetry 0 changed the grid")
+	}
+
+	other := g.Copy()
+	other.SetCount(5, 0)
+	other.SetCount(4, 1)
+	if other.Hash() == hash {
+		t.Fatal("Different puzzles have the same hash")
+	}
+}
+
+func TestTransform(t *testing.T) {
+	g, err := FromText(strings.NewReader("1 . #\no . 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := func(g *Grid) string {
+		var b bytes.Buffer
+		g.WriteText(&b)
+		return b.String()
+	}
+	for _, test := range []struct {
+		g    *Grid
+		text string
+	}{
+		{g.Rotate(), "o 1\n. .\n2 #\n"},
+		{g.Rotate().Rotate().Rotate().Rotate(), text(g)},
+		{g.Flip(), "# . 1\n2 . o\n"},
+		{g.Transpose(), "1 o\n. .\n# 2\n"},
+		{g.Crop(0, 1, 2, 2), ". #\n. 2\n"},
+		{g.Crop(1, 0, 1, 1), "o\n"},
+		{g.Pad(1, 0, 0, 1), ". . . .\n1 . # .\no . 2 .\n"},
+		{g.Pad(0, 0, 0, 0), text(g)},
+	} {
+		if s := text(test.g); s != test.text {
+			t.Fatal("Invalid transform", s, "expected", test.text)
+		}
+	}
+	if text(g) != "1 . #\no . 2\n" {
+		t.Fatal("Transform changed the grid")
+	}
+
+	for _, f := range []func(){
+		func() { g.Crop(0, 0, 3, 1) },
+		func() { g.Crop(1, 2, 1, 2) },
+		func() { g.Crop(0, 0, 0, 1) },
+		func() { g.Pad(0, -1, 0, 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("Invalid transform did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,153 @@
+package grid
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FromJanko reads a nurikabe in the format of the puzzle files on janko.at.
+// The [setup] section names the puzzle and may give its size, the [problem]
+// section has a line of tiles per row with clues and '-' for empty tiles, and
+// the optional [solution] section marks walls with 'x'.
+func FromJanko(input io.Reader) (*Grid, error) {
+	setup := make(map[string]string)
+	sections := make(map[string][][]string)
+	section := ""
+	s := bufio.NewScanner(input)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "["):
+			section = strings.ToLower(strings.Trim(line, "[]"))
+		case section == "setup":
+			if kv := strings.SplitN(line, "=", 2); len(kv) == 2 {
+				setup[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+			}
+		default:
+			sections[section] = append(sections[section], strings.Fields(line))
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if p, ok := setup["puzzle"]; ok && strings.ToLower(p) != "nurikabe" {
+		return nil, errors.New("not a nurikabe puzzle: " + p)
+	}
+	problem := sections["problem"]
+	if len(problem) == 0 {
+		return nil, errors.New("missing [problem] section")
+	}
+
+	// the size in the setup is optional, but checked against the problem
+	rows, cols := len(problem), len(problem[0])
+	var err error
+	if v, ok := setup["size"]; ok {
+		if rows, err = strconv.Atoi(v); err != nil {
+			return nil, errors.New("invalid size " + strconv.Quote(v))
+		}
+		cols = rows
+	}
+	if v, ok := setup["rows"]; ok {
+		if rows, err = strconv.Atoi(v); err != nil {
+			return nil, errors.New("invalid rows " + strconv.Quote(v))
+		}
+	}
+	if v, ok := setup["cols"]; ok {
+		if cols, err = strconv.Atoi(v); err != nil {
+			return nil, errors.New("invalid cols " + strconv.Quote(v))
+		}
+	}
+	if rows < 1 || cols < 1 {
+		return nil, errors.New("invalid size " + strconv.Itoa(rows) + "x" + strconv.Itoa(cols))
+	}
+
+	g := New(rows, cols)
+	if err := g.readJanko(problem, func(t *tile, tok string) bool {
+		if tok == "-" || tok == "." {
+			return true
+		}
+		n, err := strconv.Atoi(tok)
+		t.count = n
+		return err == nil && n > 0
+	}); err != nil {
+		return nil, errors.New("problem " + err.Error())
+	}
+	if solution, ok := sections["solution"]; ok {
+		if err := g.readJanko(solution, func(t *tile, tok string) bool {
+			if tok == "x" {
+				t.open = false
+				return t.count == 0
+			}
+			_, err := strconv.Atoi(tok)
+			return tok == "-" || tok == "." || err == nil
+		}); err != nil {
+			return nil, errors.New("solution " + err.Error())
+		}
+	}
+	return g, nil
+}
+
+// readJanko calls set with every tile of g and its token in lines, returning
+// an error when the size is wrong or set returns false.
+func (g *Grid) readJanko(lines [][]string, set func(t *tile, tok string) bool) error {
+	if len(lines) != g.rows {
+		return errors.New("has " + strconv.Itoa(len(lines)) + " rows, expected " + strconv.Itoa(g.rows))
+	}
+	for r, row := range lines {
+		if len(row) != g.cols {
+			return errors.New("row " + strconv.Itoa(r+1) + " has " + strconv.Itoa(len(row)) +
+				" tiles, expected " + strconv.Itoa(g.cols))
+		}
+		for c, tok := range row {
+			if !set(g.tiles[r*g.cols+c], tok) {
+				return errors.New("invalid tile " + strconv.Quote(tok) + " at row " +
+					strconv.Itoa(r+1) + " column " + strconv.Itoa(c+1))
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJanko writes g in the janko.at format, see FromJanko. The solution
+// section is only written when g has walls.
+func (g *Grid) WriteJanko(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	b.WriteString("[setup]\npuzzle = Nurikabe\nrows = " + strconv.Itoa(g.rows) + "\ncols = " + strconv.Itoa(g.cols) + "\n")
+	b.WriteString("[problem]\n")
+	walls := false
+	for i, t := range g.tiles {
+		tok := "-"
+		if t.count > 0 {
+			tok = strconv.Itoa(t.count)
+		}
+		walls = walls || !t.open
+		g.writeJanko(b, i, tok)
+	}
+	if walls {
+		b.WriteString("[solution]\n")
+		for i, t := range g.tiles {
+			tok := "-"
+			if !t.open {
+				tok = "x"
+			}
+			g.writeJanko(b, i, tok)
+		}
+	}
+	b.WriteString("[end]\n")
+	return b.Flush()
+}
+
+// writeJanko writes the token of tile i, separated from its row.
+func (g *Grid) writeJanko(b *bufio.Writer, i int, tok string) {
+	if i%g.cols != 0 {
+		b.WriteString(" ")
+	}
+	b.WriteString(tok)
+	if i%g.cols == g.cols-1 {
+		b.WriteString("\n")
+	}
+}
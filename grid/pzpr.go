@@ -0,0 +1,93 @@
+package grid
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FromPzpr reads a nurikabe file saved by pzprjs in the pzprv3 format: a
+// header of "pzprv3", the puzzle name, rows and columns, then a line of tiles
+// per row. Tiles are clues, '.' for unknown tiles, '#' for walls and '+' for
+// dots. Anything after the rows, like the move history, is ignored.
+func FromPzpr(input io.Reader) (*Grid, error) {
+	s := bufio.NewScanner(input)
+	var header []string
+	for len(header) < 4 && s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			header = append(header, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(header) < 4 || !strings.HasPrefix(header[0], "pzprv3") {
+		return nil, errors.New("missing pzprv3 header")
+	}
+	if header[1] != "nurikabe" {
+		return nil, errors.New("not a nurikabe file: " + header[1])
+	}
+	rows, err := strconv.Atoi(header[2])
+	if err != nil || rows < 1 {
+		return nil, errors.New("invalid rows " + strconv.Quote(header[2]))
+	}
+	cols, err := strconv.Atoi(header[3])
+	if err != nil || cols < 1 {
+		return nil, errors.New("invalid columns " + strconv.Quote(header[3]))
+	}
+
+	g := New(rows, cols)
+	for r := 0; r < rows; r++ {
+		if !s.Scan() {
+			return nil, errors.New("missing row " + strconv.Itoa(r+1))
+		}
+		row := strings.Fields(s.Text())
+		if len(row) != cols {
+			return nil, errors.New("row " + strconv.Itoa(r+1) + " has " + strconv.Itoa(len(row)) +
+				" tiles, expected " + strconv.Itoa(cols))
+		}
+		for c, tok := range row {
+			t := g.tiles[r*cols+c]
+			switch tok {
+			case ".":
+			case "#":
+				t.open = false
+			case "+":
+				t.dot = true
+			default:
+				n, err := strconv.Atoi(tok)
+				if err != nil || n < 1 {
+					return nil, errors.New("invalid tile " + strconv.Quote(tok) + " at row " +
+						strconv.Itoa(r+1) + " column " + strconv.Itoa(c+1))
+				}
+				t.count = n
+			}
+		}
+	}
+	return g, s.Err()
+}
+
+// WritePzpr writes g in the pzprv3 format, see FromPzpr.
+func (g *Grid) WritePzpr(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	b.WriteString("pzprv3\nnurikabe\n" + strconv.Itoa(g.rows) + "\n" + strconv.Itoa(g.cols) + "\n")
+	for i, t := range g.tiles {
+		switch {
+		case t.count > 0:
+			b.WriteString(strconv.Itoa(t.count))
+		case !t.open:
+			b.WriteString("#")
+		case t.dot:
+			b.WriteString("+")
+		default:
+			b.WriteString(".")
+		}
+		b.WriteString(" ")
+		if i%g.cols == g.cols-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.Flush()
+}
@@ -0,0 +1,117 @@
+package grid
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Text format tiles. Each row of the grid is a line of tiles separated by
+// spaces, clues are written as numbers:
+//
+//	2 . # .
+//	. # . 10
+//
+// Rows without spaces are read one character per tile, which only allows
+// single digit clues. Grids where every row is a single tile, like those with
+// one column, are read a row per tile.
+const (
+	TextUnknown = "."
+	TextWall    = "#"
+	TextDot     = "o"
+)
+
+// FromText reads a grid in the text format. Blank lines are skipped.
+func FromText(input io.Reader) (*Grid, error) {
+	var rows [][]string
+	single := true // every row is one tile
+	s := bufio.NewScanner(input)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		row := strings.Fields(line)
+		single = single && len(row) == 1 && textTile(row[0])
+		rows = append(rows, row)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("empty grid")
+	}
+	for r, row := range rows {
+		if len(row) == 1 && !single {
+			row = strings.Split(row[0], "")
+			rows[r] = row
+		}
+		if len(row) != len(rows[0]) {
+			return nil, errors.New("row " + strconv.Itoa(r+1) + " has " + strconv.Itoa(len(row)) +
+				" tiles, expected " + strconv.Itoa(len(rows[0])))
+		}
+	}
+
+	g := New(len(rows), len(rows[0]))
+	for r, row := range rows {
+		for c, tok := range row {
+			i := r*g.cols + c
+			switch tok {
+			case TextUnknown:
+			case TextWall:
+				g.tiles[i].open = false
+			case TextDot:
+				g.tiles[i].dot = true
+			default:
+				n, err := strconv.Atoi(tok)
+				if err != nil || n < 1 {
+					return nil, errors.New("invalid tile " + strconv.Quote(tok) + " at row " +
+						strconv.Itoa(r+1) + " column " + strconv.Itoa(c+1))
+				}
+				g.tiles[i].count = n
+			}
+		}
+	}
+	return g, nil
+}
+
+// textTile returns true if tok is a single tile of the text format.
+func textTile(tok string) bool {
+	if tok == TextUnknown || tok == TextWall || tok == TextDot {
+		return true
+	}
+	n, err := strconv.Atoi(tok)
+	return err == nil && n > 0
+}
+
+// WriteText writes g in the text format, with tiles padded to line up.
+func (g *Grid) WriteText(w io.Writer) error {
+	width := 1
+	for _, t := range g.tiles {
+		if n := len(strconv.Itoa(t.count)); n > width {
+			width = n
+		}
+	}
+	b := bufio.NewWriter(w)
+	for i, t := range g.tiles {
+		tok := TextUnknown
+		switch {
+		case t.count > 0:
+			tok = strconv.Itoa(t.count)
+		case !t.open:
+			tok = TextWall
+		case t.dot:
+			tok = TextDot
+		}
+		if i%g.cols != 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(strings.Repeat(" ", width-len(tok)) + tok)
+		if i%g.cols == g.cols-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.Flush()
+}
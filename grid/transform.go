@@ -0,0 +1,75 @@
+package grid
+
+import "github.com/ostlerc/nurikabe/geom"
+
+// Symmetries is the number of ways to rotate and reflect a grid, see
+// Symmetry.
+const Symmetries = 8
+
+// Symmetry returns a copy of g moved by one of its Symmetries: s&1 reflects
+// it left to right, s&2 top to bottom, then s&4 swaps rows and columns.
+// Symmetry 0 is g itself. See also Rotate, Flip and Transpose.
+func (g *Grid) Symmetry(s int) *Grid {
+	rows, cols := g.rows, g.cols
+	if s&4 != 0 {
+		rows, cols = cols, rows
+	}
+	t := New(rows, cols)
+	for i, tl := range g.tiles {
+		c := g.Size().Cell(i)
+		if s&1 != 0 {
+			c.Col = g.cols - 1 - c.Col
+		}
+		if s&2 != 0 {
+			c.Row = g.rows - 1 - c.Row
+		}
+		if s&4 != 0 {
+			c.Row, c.Col = c.Col, c.Row
+		}
+		*t.tiles[t.Size().Index(c)] = *tl
+	}
+	return t
+}
+
+// Rotate returns a copy of g turned a quarter clockwise.
+func (g *Grid) Rotate() *Grid {
+	return g.Symmetry(6)
+}
+
+// Flip returns a copy of g reflected left to right.
+func (g *Grid) Flip() *Grid {
+	return g.Symmetry(1)
+}
+
+// Transpose returns a copy of g with rows and columns swapped.
+func (g *Grid) Transpose() *Grid {
+	return g.Symmetry(4)
+}
+
+// Crop returns the rows by cols part of g starting at row top and column left.
+// It panics if the part does not fit in g.
+func (g *Grid) Crop(top, left, rows, cols int) *Grid {
+	if top < 0 || left < 0 || rows < 1 || cols < 1 || top+rows > g.rows || left+cols > g.cols {
+		panic("grid: crop out of range")
+	}
+	c := New(rows, cols)
+	for i, t := range c.tiles {
+		at := c.Size().Cell(i).Add(geom.Cell{Row: top, Col: left})
+		*t = *g.tiles[g.Size().Index(at)]
+	}
+	return c
+}
+
+// Pad returns a copy of g with open tiles added around it. It panics if a
+// side is negative.
+func (g *Grid) Pad(top, left, bottom, right int) *Grid {
+	if top < 0 || left < 0 || bottom < 0 || right < 0 {
+		panic("grid: negative padding")
+	}
+	p := New(top+g.rows+bottom, left+g.cols+right)
+	for i, t := range g.tiles {
+		at := g.Size().Cell(i).Add(geom.Cell{Row: top, Col: left})
+		*p.tiles[p.Size().Index(at)] = *t
+	}
+	return p
+}
@@ -0,0 +1,121 @@
+package grid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// URLPrefix starts the urls written by URL. Urls from pzv.jp and other
+// pzprjs sites are read too.
+const URLPrefix = "https://puzz.link/p?nurikabe/"
+
+// FromURL reads the clues of a pzprjs nurikabe url, in the form
+// ...?nurikabe/<columns>/<rows>/<clues>. Clues are written in order, one hex
+// digit for clues below 16, '-' and two digits below 256, '+' and three
+// digits below 4096, '=' and '%' and three digits for the next 4096 each.
+// Letters 'g' to 'z' skip 1 to 20 tiles without a clue.
+func FromURL(url string) (*Grid, error) {
+	url = strings.TrimSpace(url)
+	q := strings.Index(url, "?")
+	if q == -1 {
+		return nil, errors.New("no puzzle in url")
+	}
+	parts := strings.Split(url[q+1:], "/")
+	if parts[0] != "nurikabe" {
+		return nil, errors.New("not a nurikabe url")
+	}
+	parts = parts[1:]
+	if len(parts) > 0 {
+		if _, err := strconv.Atoi(parts[0]); err != nil { // pzprjs flags
+			parts = parts[1:]
+		}
+	}
+	if len(parts) < 2 {
+		return nil, errors.New("no size in url")
+	}
+	cols, err := strconv.Atoi(parts[0])
+	if err != nil || cols < 1 {
+		return nil, errors.New("invalid width " + strconv.Quote(parts[0]))
+	}
+	rows, err := strconv.Atoi(parts[1])
+	if err != nil || rows < 1 {
+		return nil, errors.New("invalid height " + strconv.Quote(parts[1]))
+	}
+
+	g := New(rows, cols)
+	body := ""
+	if len(parts) > 2 {
+		body = parts[2]
+	}
+	for i, t := 0, 0; i < len(body) && t < len(g.tiles); t++ {
+		digits, base := 1, 0
+		switch ch := body[i]; {
+		case ch >= 'g' && ch <= 'z':
+			t += int(ch - 'g')
+			i++
+			continue
+		case ch == '.':
+			return nil, errors.New("unknown clue at tile " + strconv.Itoa(t+1))
+		case ch == '-':
+			i, digits = i+1, 2
+		case ch == '+':
+			i, digits = i+1, 3
+		case ch == '=':
+			i, digits, base = i+1, 3, 4096
+		case ch == '%':
+			i, digits, base = i+1, 3, 8192
+		}
+		if i+digits > len(body) {
+			return nil, errors.New("url ends inside a clue")
+		}
+		n, err := strconv.ParseUint(body[i:i+digits], 16, 16)
+		if err != nil || int(n)+base < 1 {
+			return nil, errors.New("invalid clue " + strconv.Quote(body[i:i+digits]) + " at tile " + strconv.Itoa(t+1))
+		}
+		g.tiles[t].count = int(n) + base
+		i += digits
+	}
+	return g, nil
+}
+
+// URL returns the clues of g as a puzz.link url, see FromURL.
+func (g *Grid) URL() (string, error) {
+	var b bytes.Buffer
+	b.WriteString(URLPrefix + strconv.Itoa(g.cols) + "/" + strconv.Itoa(g.rows) + "/")
+	skip := 0
+	for i, t := range g.tiles {
+		if t.count == 0 {
+			skip++
+			if skip == 20 {
+				b.WriteByte('z')
+				skip = 0
+			}
+			continue
+		}
+		if skip > 0 {
+			b.WriteString(strconv.FormatInt(int64(15+skip), 36))
+			skip = 0
+		}
+		switch {
+		case t.count < 16:
+			fmt.Fprintf(&b, "%x", t.count)
+		case t.count < 256:
+			fmt.Fprintf(&b, "-%02x", t.count)
+		case t.count < 4096:
+			fmt.Fprintf(&b, "+%03x", t.count)
+		case t.count < 8192:
+			fmt.Fprintf(&b, "=%03x", t.count-4096)
+		case t.count < 12288:
+			fmt.Fprintf(&b, "%%%03x", t.count-8192)
+		default:
+			return "", errors.New("clue " + strconv.Itoa(t.count) + " at tile " + strconv.Itoa(i+1) + " is too large for a url")
+		}
+	}
+	if skip > 0 {
+		b.WriteString(strconv.FormatInt(int64(15+skip), 36))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+// mark is what the player put on a tile.
+type mark int
+
+const (
+	markOpen mark = iota
+	markClosed
+	markDot
+)
+
+// change is one tile going from one mark to another.
+type change struct {
+	i        int
+	from, to mark
+}
+
+// move is the changes made by a single player action, undone as one.
+type move []change
+
+// history keeps the moves of the current game for undo and redo.
+type history struct {
+	done   []move
+	undone []move
+}
+
+func (h *history) push(m move) {
+	h.done = append(h.done, m)
+	h.undone = nil
+}
+
+// undo returns the last move played, reversed.
+func (h *history) undo() (move, bool) {
+	if len(h.done) == 0 {
+		return nil, false
+	}
+	m := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, m)
+	rev := make(move, len(m))
+	for i, c := range m {
+		rev[len(m)-1-i] = change{c.i, c.to, c.from}
+	}
+	return rev, true
+}
+
+// redo returns the last move undone.
+func (h *history) redo() (move, bool) {
+	if len(h.undone) == 0 {
+		return nil, false
+	}
+	m := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, m)
+	return m, true
+}
+
+// clickCycle is the order of marks a click steps a tile through.
+type clickCycle struct {
+	name  string
+	marks []mark
+}
+
+var clickCycles = []*clickCycle{
+	{"wall", []mark{markOpen, markClosed}},
+	{"wall, dot", []mark{markOpen, markClosed, markDot}},
+	{"dot, wall", []mark{markOpen, markDot, markClosed}},
+}
+
+// cycleByName returns the click cycle called name, or the first one.
+func cycleByName(name string) *clickCycle {
+	for _, c := range clickCycles {
+		if c.name == name {
+			return c
+		}
+	}
+	return clickCycles[0]
+}
+
+// next returns the mark after m, or the one before it when back is set. The
+// other button of the two mark cycle toggles dots instead.
+func (c *clickCycle) next(m mark, back bool) mark {
+	if len(c.marks) == 2 && back {
+		if m == markDot {
+			return markOpen
+		}
+		return markDot
+	}
+	i := 0
+	for j, x := range c.marks {
+		if x == m {
+			i = j
+		}
+	}
+	if back {
+		i += len(c.marks) - 1
+	} else {
+		i++
+	}
+	return c.marks[i%len(c.marks)]
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"github.com/ostlerc/nurikabe/geom"
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/stats"
+
+	"gopkg.in/qml.v1"
+)
+
+// Commands sent by qml/game.qml for key presses.
+const (
+	keyLeft   = "left"
+	keyRight  = "right"
+	keyUp     = "up"
+	keyDown   = "down"
+	keyToggle = "toggle"
+	keyDot    = "dot"
+	keyUndo   = "undo"
+	keyRedo   = "redo"
+	keyHint   = "hint"
+	keyPause  = "pause"
+	keyBack   = "back"
+	keyRotate = "rotate"
+	keyFlip   = "flip"
+)
+
+// cursorDirections are the moves of the arrow keys.
+var cursorDirections = map[string]geom.Direction{
+	keyLeft:  geom.Left,
+	keyRight: geom.Right,
+	keyUp:    geom.Up,
+	keyDown:  geom.Down,
+}
+
+func (w *window) KeyCommand(cmd string) {
+	if cmd == keyBack {
+		if w.currentMode != mainMenu {
+			w.ToolButtonClicked()
+		}
+		return
+	}
+	switch w.currentMode {
+	case nurikabePage:
+		w.playKey(cmd)
+	case editorPage:
+		if !w.typing {
+			w.editKey(cmd)
+		}
+	default:
+		w.menuKey(cmd)
+	}
+}
+
+func (w *window) playKey(cmd string) {
+	if w.paused {
+		if cmd == keyPause || cmd == keyToggle {
+			w.resume()
+		}
+		return
+	}
+	switch cmd {
+	case keyPause:
+		w.pause()
+	case keyToggle:
+		if w.cursor != -1 {
+			w.TileChecked(w.cursor)
+		}
+	case keyDot:
+		if w.cursor != -1 {
+			w.TileDotted(w.cursor)
+		}
+	case keyUndo:
+		w.undo()
+	case keyRedo:
+		w.redo()
+	case keyHint:
+		w.showHint()
+	default:
+		w.moveCursor(cmd, w.objs, w.g.Columns(), "cursor")
+	}
+}
+
+func (w *window) editKey(cmd string) {
+	switch cmd {
+	case keyToggle:
+		if w.cursor != -1 {
+			w.TileEdited(w.cursor, 1)
+		}
+	case keyDot:
+		if w.cursor != -1 {
+			w.TileEdited(w.cursor, -1)
+		}
+	case keyRotate:
+		w.transformEdit((*grid.Grid).Rotate)
+	case keyFlip:
+		w.transformEdit((*grid.Grid).Flip)
+	default:
+		w.moveCursor(cmd, w.objs, w.edit.Columns(), "cursor")
+	}
+}
+
+func (w *window) menuKey(cmd string) {
+	buttons := w.buttons()
+	if cmd == keyToggle && w.cursor != -1 && w.cursor < len(buttons) {
+		w.OnBtnClicked(buttons[w.cursor].String("data"))
+		return
+	}
+	w.moveCursor(cmd, buttons, w.qGameGrid().Int("columns"), "selected")
+}
+
+// buttons returns the buttons of the current page in layout order.
+func (w *window) buttons() []qml.Object {
+	switch {
+	case w.typing:
+		return nil
+	case w.currentMode == statsPage:
+		return w.objs[:len(stats.Policies)]
+	case w.currentMode == rulesPage || w.currentMode == generatingPage:
+		return nil
+	}
+	return w.objs
+}
+
+// moveCursor moves w.cursor over objs laid out in cols columns and sets prop
+// on the object under it. The first move only shows the cursor.
+func (w *window) moveCursor(cmd string, objs []qml.Object, cols int, prop string) {
+	if len(objs) == 0 || cols < 1 {
+		return
+	}
+	d, ok := cursorDirections[cmd]
+	if !ok {
+		return
+	}
+	c := w.cursor
+	size := geom.Size{Rows: (len(objs) + cols - 1) / cols, Cols: cols} // the last row can be short
+	if c == -1 {
+		c = 0
+	} else if x, ok := size.Step(c, d); ok && x < len(objs) {
+		c = x
+	}
+	if w.cursor != -1 {
+		objs[w.cursor].Set(prop, false)
+	}
+	w.cursor = c
+	objs[c].Set(prop, true)
+}
@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
+	"github.com/ostlerc/nurikabe/draw"
 	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/pack"
+	"github.com/ostlerc/nurikabe/profile"
 	"github.com/ostlerc/nurikabe/stats"
 	"github.com/ostlerc/nurikabe/validator"
 
@@ -13,24 +17,48 @@ import (
 )
 
 const (
-	statsFile = ".stats.json"
-	levelDir  = "levels/"
+	statsFile       = "stats.json"
+	legacyStatsFile = ".stats.json"
+	levelDir        = "levels/"
+	profileDir      = ".profiles/"
 )
 
 type window struct {
 	g       *grid.Grid
+	edit    *grid.Grid // puzzle in the editor
 	v       validator.GridValidator
 	objs    []qml.Object
+	actions []qml.Object
 	records *stats.Records
+	profile *profile.Profile
+	theme   *draw.Theme
+	context *qml.Context
 
-	tileComponent qml.Object
-	btnComponent  qml.Object
-	txtComponent  qml.Object
-	winComponent  *qml.Window
+	solution       []bool // closed tiles of the first solution, once solved
+	uniqueSolution bool
+	history        history
+	hints          int
+	clock          clock
+	paused         bool
+
+	cursor int  // keyboard selected tile or button, -1 for none
+	typing bool // the page is waiting for text input
+
+	tileComponent   qml.Object
+	btnComponent    qml.Object
+	txtComponent    qml.Object
+	inputComponent  qml.Object
+	busyComponent   qml.Object
+	actionComponent qml.Object
+	winComponent    *qml.Window
 
 	currentDifficulty string
 	currentBoard      string
+	currentLevel      int
 	currentMode       gameMode
+
+	generation int           // incremented for every puzzle generated or checked
+	editCancel chan struct{} // closed to stop the check of the edited grid
 }
 
 type gameMode int
@@ -42,13 +70,37 @@ const (
 	rulesPage
 	statsPage
 	nurikabePage
+	profileSelect
+	endlessSelect
+	generatingPage
+	editorPage
+	packSelect
+	settingsPage
 )
 
 const (
-	MenuPlay  = "Play"
-	MenuStats = "Records"
-	MenuRules = "Rules"
-	MenuExit  = "Exit"
+	MenuPlay     = "Play"
+	MenuDaily    = "Daily"
+	MenuEndless  = "Endless"
+	MenuEditor   = "Editor"
+	MenuProfile  = "Profile"
+	MenuStats    = "Records"
+	MenuSettings = "Settings"
+	MenuRules    = "Rules"
+	MenuExit     = "Exit"
+)
+
+const newProfile = "+ New"
+
+const (
+	actionNext   = "next"
+	actionSave   = "save"
+	actionAssist = "assist"
+	actionCheck  = "check"
+	actionHint   = "hint"
+	actionReveal = "reveal"
+	actionPause  = "pause"
+	actionResume = "resume"
 )
 
 const rulesText = `Each puzzle consists of a grid containing clues in various places.` +
@@ -59,7 +111,7 @@ const rulesText = `Each puzzle consists of a grid containing clues in various pl
 	` There are no wall areas of 2x2 or larger.` +
 	` When completed, all walls form a continuous path.`
 
-var MenuItems = []string{MenuPlay, MenuStats, MenuRules, MenuExit}
+var MenuItems = []string{MenuPlay, MenuDaily, MenuEndless, MenuEditor, MenuProfile, MenuStats, MenuSettings, MenuRules, MenuExit}
 
 func NewMainWindow(engine *qml.Engine) (*window, error) {
 	windowComponent, err := engine.LoadFile("qml/window.qml")
@@ -87,18 +139,41 @@ func NewMainWindow(engine *qml.Engine) (*window, error) {
 		return nil, err
 	}
 
+	window.inputComponent, err = engine.LoadFile("qml/input.qml")
+	if err != nil {
+		return nil, err
+	}
+
+	window.busyComponent, err = engine.LoadFile("qml/busy.qml")
+	if err != nil {
+		return nil, err
+	}
+
+	window.actionComponent, err = engine.LoadFile("qml/action.qml")
+	if err != nil {
+		return nil, err
+	}
+
 	return window, nil
 }
 
 func (w *window) setGameMode(mode gameMode) {
+	w.stopEditCheck() // the editor page starts a new one
 	w.currentMode = mode
+	w.cursor = -1
+	w.typing = false
+	w.paused = false
+	if mode != nurikabePage {
+		w.clock.pause()
+	}
 	w.clearGrid()
+	w.clearActions()
 	w.setSource("qml/game.qml") //reload screen
 
 	w.qToolBtn().Set("visible", mode != mainMenu)
 	w.qStepsText().Set("visible", mode == nurikabePage)
-	w.qTimeText().Set("visible", mode == nurikabePage)
-	w.qRecordText().Set("visible", mode == nurikabePage)
+	w.qTimeText().Set("visible", mode == nurikabePage && !w.profile.Settings.HideTimer)
+	w.qRecordText().Set("visible", mode == nurikabePage || mode == editorPage)
 
 	switch mode {
 	case mainMenu:
@@ -113,6 +188,18 @@ func (w *window) setGameMode(mode gameMode) {
 		w.buildRules()
 	case statsPage:
 		w.buildStats()
+	case profileSelect:
+		w.buildProfileSelect()
+	case endlessSelect:
+		w.buildEndlessSelect()
+	case generatingPage:
+		w.buildGenerating()
+	case editorPage:
+		w.buildEditor()
+	case packSelect:
+		w.buildPackSelect()
+	case settingsPage:
+		w.buildSettings()
 	}
 }
 
@@ -120,14 +207,38 @@ func (w *window) ToolButtonClicked() {
 	switch w.currentMode {
 	case rulesPage:
 		fallthrough
+	case settingsPage:
+		fallthrough
+	case profileSelect:
+		fallthrough
 	case statsPage:
 		w.setGameMode(mainMenu)
+	case editorPage:
+		if w.typing {
+			w.setGameMode(editorPage)
+			return
+		}
+		w.setGameMode(mainMenu)
 	case difficultySelect:
+		fallthrough
+	case endlessSelect:
 		w.setGameMode(mainMenu)
+	case packSelect:
+		w.setGameMode(editorPage)
+	case generatingPage:
+		w.setGameMode(endlessSelect)
 	case levelSelect:
 		w.setGameMode(difficultySelect)
 	case nurikabePage:
-		w.setGameMode(levelSelect)
+		w.saveGame()
+		switch w.currentDifficulty {
+		case stats.DailyDifficulty:
+			w.setGameMode(mainMenu)
+		case endlessDifficulty:
+			w.setGameMode(endlessSelect)
+		default:
+			w.setGameMode(levelSelect)
+		}
 	}
 }
 
@@ -137,12 +248,22 @@ func (w *window) OnBtnClicked(data string) {
 		switch data {
 		case MenuPlay:
 			w.setGameMode(difficultySelect)
+		case MenuDaily:
+			w.playDaily()
+		case MenuEndless:
+			w.setGameMode(endlessSelect)
+		case MenuEditor:
+			w.setGameMode(editorPage)
+		case MenuProfile:
+			w.setGameMode(profileSelect)
 		case MenuStats:
 			w.setGameMode(statsPage)
+		case MenuSettings:
+			w.setGameMode(settingsPage)
 		case MenuRules:
 			w.setGameMode(rulesPage)
 		case MenuExit:
-			w.records.Save(statsFile)
+			w.saveProfile()
 			os.Exit(0)
 		}
 	case difficultySelect:
@@ -150,7 +271,32 @@ func (w *window) OnBtnClicked(data string) {
 		w.setGameMode(levelSelect)
 	case levelSelect:
 		w.currentBoard = data
-		w.loadLevel(levelDir + w.currentDifficulty + "/" + data)
+		w.currentLevel = levelInt(data)
+		w.loadLevel(w.levelDir() + w.currentDifficulty + "/" + data)
+	case endlessSelect:
+		w.currentDifficulty = endlessDifficulty
+		w.currentBoard = data
+		w.playEndless()
+	case statsPage:
+		if p, ok := stats.PolicyByName(data); ok {
+			w.records.SetPolicy(p)
+			w.profile.Settings.Policy = p.Name()
+			w.setGameMode(statsPage)
+		}
+	case profileSelect:
+		if data == newProfile {
+			w.buildProfileInput()
+			return
+		}
+		w.switchProfile(data)
+	case settingsPage:
+		w.changeSetting(data)
+	case packSelect:
+		if data == newPack {
+			w.buildPackInput()
+			return
+		}
+		w.saveEdit(data)
 	case nurikabePage: //This is handled by TileChecked
 		panic("Err")
 	case rulesPage:
@@ -159,23 +305,215 @@ func (w *window) OnBtnClicked(data string) {
 }
 
 func (w *window) TileChecked(i int) {
+	w.paint([]int{i}, false)
+}
+
+// TileDotted is a right click on tile i, by default marking or unmarking it
+// as part of an island.
+func (w *window) TileDotted(i int) {
+	w.paint([]int{i}, true)
+}
+
+// TilesChecked is the batch variant of TileChecked and TileDotted for tiles
+// painted in one drag.
+func (w *window) TilesChecked(list *qml.List, secondary bool) {
+	var tiles []int
+	list.Convert(&tiles)
+	w.paint(tiles, secondary)
+}
+
+// PaintState returns the tile state a drag starting at tile i paints, for
+// qml/tile.qml to show while dragging.
+func (w *window) PaintState(i int, secondary bool) string {
+	switch w.nextMark(i, secondary) {
+	case markClosed:
+		return "closed"
+	case markDot:
+		return "dot"
+	}
+	return "open"
+}
+
+// nextMark returns the mark a click with the main or secondary button
+// gives tile i.
+func (w *window) nextMark(i int, secondary bool) mark {
+	if w.profile.Settings.SwapClick {
+		secondary = !secondary
+	}
+	return cycleByName(w.profile.Settings.Cycle).next(w.mark(i), secondary)
+}
+
+// paint gives every tile the mark that clicking the first tile would, as a
+// single move.
+func (w *window) paint(tiles []int, secondary bool) {
+	if len(tiles) == 0 || w.g.Count(tiles[0]) > 0 || w.qStatus().Bool("finished") {
+		w.showViolations()
+		return
+	}
+	to := w.nextMark(tiles[0], secondary)
+	var m move
+	for _, i := range tiles {
+		if from := w.mark(i); w.g.Count(i) == 0 && from != to {
+			m = append(m, change{i, from, to})
+		}
+	}
+	if len(m) == 0 {
+		return
+	}
+	w.play(m)
+}
+
+func (w *window) mark(i int) mark {
+	switch {
+	case !w.g.Open(i):
+		return markClosed
+	case w.g.Dot(i):
+		return markDot
+	}
+	return markOpen
+}
+
+// play makes m the latest move of the current game, along with the dots of
+// islands it completes when auto dot is on.
+func (w *window) play(m move) {
+	w.set(m)
+	if w.profile.Settings.AutoDot {
+		for i, complete := range validator.CompleteIslands(w.g) {
+			if complete && w.g.Count(i) == 0 && w.mark(i) == markOpen {
+				c := change{i, markOpen, markDot}
+				w.set(move{c})
+				m = append(m, c)
+			}
+		}
+	}
+	w.history.push(m)
+	w.moved()
+}
+
+func (w *window) undo() {
+	if w.qStatus().Bool("finished") {
+		return
+	}
+	if m, ok := w.history.undo(); ok {
+		w.apply(m)
+	}
+}
+
+func (w *window) redo() {
+	if w.qStatus().Bool("finished") {
+		return
+	}
+	if m, ok := w.history.redo(); ok {
+		w.apply(m)
+	}
+}
+
+// apply sets the tiles changed by an undone or redone move.
+func (w *window) apply(m move) {
+	w.set(m)
+	w.moved()
+}
+
+func (w *window) set(m move) {
+	for _, c := range m {
+		switch c.to {
+		case markOpen:
+			w.g.SetOpen(c.i, true)
+		case markClosed:
+			w.g.SetOpen(c.i, false)
+		case markDot:
+			w.g.SetDot(c.i, true)
+		}
+	}
+}
+
+// moved counts a move as one step and shows its outcome.
+func (w *window) moved() {
 	w.qStepsText().Set("moves", w.qStepsText().Int("moves")+1)
-	w.g.Toggle(i)
+	w.showViolations()
+	w.checkWin()
+}
+
+func (w *window) checkWin() {
 	if w.v.CheckWin(w.g) {
-		w.records.Log(w.currentDifficulty, levelInt(w.currentBoard), w.qStepsText().Int("moves"), w.qTimeText().Int("seconds"))
-		w.records.Save(statsFile)
+		if w.currentDifficulty == endlessDifficulty {
+			w.endlessWon()
+			return
+		}
+		w.records.Log(w.currentDifficulty, w.currentLevel, stats.Result{
+			Steps:   w.qStepsText().Int("moves"),
+			Seconds: w.clock.seconds(),
+			Hints:   w.hints,
+		})
+		delete(w.profile.Saves, w.saveKey())
+		w.saveProfile()
 		w.setStatus("Nurikabe - Completed")
-		w.qRecordText().Set("text", w.records.String(w.currentDifficulty, levelInt(w.currentBoard)))
+		w.qRecordText().Set("text", w.recordText())
 		w.setTimer(false)
+		w.clearActions()
 	}
 }
 
-func levelInt(file string) int {
-	ret, err := strconv.Atoi(levelStr(file))
-	if err != nil {
-		panic(err)
+func (w *window) OnActionClicked(data string) {
+	switch data {
+	case actionNext:
+		w.playEndless()
+	case actionSave:
+		w.saveEndless()
+	case actionAssist:
+		w.profile.Settings.Assist = (w.profile.Settings.Assist + 1) % len(assistNames)
+		w.clearActions()
+		w.addPlayActions()
+		w.showViolations()
+	case actionCheck:
+		w.checkSolution()
+	case actionHint:
+		w.showHint()
+	case actionPause:
+		w.pause()
+	case actionResume:
+		w.resume()
+	case actionReveal:
+		w.revealSolution()
+	case actionAddRow:
+		w.resizeEdit(w.edit.Rows()+1, w.edit.Columns())
+	case actionRemoveRow:
+		w.resizeEdit(w.edit.Rows()-1, w.edit.Columns())
+	case actionAddCol:
+		w.resizeEdit(w.edit.Rows(), w.edit.Columns()+1)
+	case actionRemoveCol:
+		w.resizeEdit(w.edit.Rows(), w.edit.Columns()-1)
+	case actionSaveLevel:
+		w.setGameMode(packSelect)
+	case actionURL:
+		w.buildURLInput()
+	}
+}
+
+func (w *window) OnInputAccepted(text string) {
+	switch w.currentMode {
+	case profileSelect:
+		if !profile.Valid(text) {
+			w.setStatus("Nurikabe - Invalid name")
+			return
+		}
+		w.switchProfile(text)
+	case packSelect:
+		if !profile.Valid(text) {
+			w.setStatus("Nurikabe - Invalid name")
+			return
+		}
+		w.saveEdit(pack.NewName(dirs(w.levelDir()), text))
+	case settingsPage:
+		w.setLevelDir(text)
+	case editorPage:
+		w.importURL(text)
 	}
-	return ret
+}
+
+func levelInt(file string) int {
+	n, _ := pack.Level(file)
+	return n
 }
 
 func levelStr(file string) string {
@@ -190,15 +528,81 @@ func (w *window) loadLevel(file string) {
 			w.setGameMode(levelSelect)
 			return
 		} else {
-			w.g, err = grid.FromJson(r)
+			g, err := grid.FromJson(r)
+			r.Close()
 			if err != nil {
-				panic(err)
+				fmt.Fprintln(os.Stderr, "failed to read level "+file, err)
+				w.setGameMode(levelSelect)
+				w.setStatus("Nurikabe - Invalid level")
+				return
 			}
-			w.setGameMode(nurikabePage)
+			w.g = g
+			w.startGame()
+		}
+	}
+}
+
+func (w *window) playDaily() {
+	now := time.Now()
+	w.currentDifficulty = stats.DailyDifficulty
+	w.currentBoard = now.Format("2006-01-02")
+	w.currentLevel = stats.DateLevel(now)
+	w.g = grid.Daily(w.v, now)
+	w.startGame()
+}
+
+// startGame shows w.g, resuming the saved game for the current level if any.
+// Saves of another grid are dropped.
+func (w *window) startGame() {
+	w.solution = nil
+	w.history = history{}
+	w.hints = 0
+	w.clock = clock{}
+	w.generation++
+	save, ok := w.profile.Saves[w.saveKey()]
+	if ok && !savedOn(save, w.g) {
+		delete(w.profile.Saves, w.saveKey())
+		ok = false
+	}
+	if ok {
+		for _, i := range save.Closed {
+			w.g.Toggle(i)
+		}
+		for _, i := range save.Dots {
+			w.g.SetDot(i, true)
 		}
+		w.hints = save.Hints
+		w.clock.elapsed = time.Duration(save.Seconds) * time.Second
+	}
+	w.setGameMode(nurikabePage)
+	if ok {
+		w.qStepsText().Set("moves", save.Steps)
 	}
 }
 
+func (w *window) levelTitle() string {
+	switch w.currentDifficulty {
+	case stats.DailyDifficulty:
+		return "Daily " + w.currentBoard
+	case endlessDifficulty:
+		return "Endless " + w.currentBoard
+	}
+	return pack.Name(w.currentDifficulty) + " " + levelStr(w.currentBoard)
+}
+
+func (w *window) recordText() string {
+	if w.currentDifficulty == endlessDifficulty {
+		return ""
+	}
+	s := w.records.String(w.currentDifficulty, w.currentLevel)
+	if w.currentDifficulty == stats.DailyDifficulty {
+		if streak := w.records.Streak(time.Now()); streak > 0 {
+			s += "streak: " + strconv.Itoa(streak) + " days  "
+		}
+	}
+	return s
+}
+
 func (w *window) clearGrid() {
 	for i, _ := range w.objs {
 		w.objs[i].Set("visible", false)
@@ -208,33 +612,69 @@ func (w *window) clearGrid() {
 }
 
 func (w *window) buildNurikabeGrid() {
-	w.setStatus("Nurikabe - " + w.currentDifficulty[2:] + " " + levelStr(w.currentBoard))
-	w.qRecordText().Set("text", w.records.String(w.currentDifficulty, levelInt(w.currentBoard)))
-	w.qGameGrid().Set("spacing", 1)
+	w.setStatus("Nurikabe - " + w.levelTitle())
+	w.qRecordText().Set("text", w.recordText())
 	w.qToolBtn().Set("text", "Back")
 	w.setTimer(true)
+	w.buildTiles(w.g)
+	w.addPlayActions()
+	w.showViolations()
+}
 
-	l := w.g.Rows() * w.g.Columns()
-	w.qGameGrid().Set("columns", w.g.Columns())
+func (w *window) addPlayActions() {
+	w.addAction("Pause", actionPause)
+	w.addAction("Check", actionCheck)
+	w.addAction("Hint", actionHint)
+	w.addAction("Reveal", actionReveal)
+	w.addAction("Assist: "+assistNames[w.profile.Settings.Assist], actionAssist)
+}
 
-	w.clearGrid()
-	w.objs = make([]qml.Object, l, l)
-	dimension := w.g.Columns()
-	if rows := w.g.Rows(); rows > dimension {
-		dimension = rows
+// showViolations highlights the tiles breaking a rule at the assist level.
+func (w *window) showViolations() {
+	var v []validator.Violation
+	mask := validator.Violation(0)
+	switch w.profile.Settings.Assist {
+	case profile.AssistMistakes:
+		mask = validator.WallBlock | validator.SmallIsland
+	case profile.AssistAll:
+		mask = ^mask
 	}
-	windowDim := w.winComponent.Root().Int("width") - 50
-	if height := w.winComponent.Root().Int("height") - 60; height < windowDim {
-		windowDim = height
+	if mask != 0 {
+		v = validator.Violations(w.g)
 	}
-	dimension = windowDim / dimension
+	for i, obj := range w.objs {
+		state := "open"
+		switch w.mark(i) {
+		case markClosed:
+			state = "closed"
+		case markDot:
+			state = "dot"
+		}
+		if v != nil && v[i]&mask != 0 {
+			state += "Error"
+		}
+		obj.Set("state", state)
+	}
+}
+
+// buildTiles fills the game grid with the tiles of g. qml/game.qml sizes
+// them to fit the window and handles zoom.
+func (w *window) buildTiles(g *grid.Grid) {
+	w.qGameGrid().Set("spacing", 1)
+	l := g.Rows() * g.Columns()
+	w.qGameGrid().Set("columns", g.Columns())
+	w.qGameGrid().Set("rows", g.Rows())
+
+	w.clearGrid()
+	w.objs = make([]qml.Object, l, l)
 	for i := 0; i < l; i++ {
 		w.objs[i] = w.tileComponent.Create(nil)
 		w.objs[i].Set("parent", w.qGameGrid())
 		w.objs[i].Set("index", i)
-		w.objs[i].Set("count", w.g.Count(i))
-		w.objs[i].Set("width", dimension)
-		w.objs[i].Set("height", dimension)
+		w.objs[i].Set("count", g.Count(i))
+		if !g.Open(i) {
+			w.objs[i].Set("state", "closed")
+		}
 	}
 }
 
@@ -248,6 +688,9 @@ func (w *window) buildMainMenu() {
 		w.objs[i] = w.btnComponent.Create(nil)
 		w.objs[i].Set("parent", w.qGameGrid())
 		w.objs[i].Set("text", name)
+		if name == MenuProfile {
+			w.objs[i].Set("text", name+": "+w.profile.Name)
+		}
 		w.objs[i].Set("data", name)
 		w.objs[i].Set("alignCenter", true)
 		w.objs[i].Set("width", w.winComponent.Root().Int("width")-150)
@@ -256,22 +699,24 @@ func (w *window) buildMainMenu() {
 
 func (w *window) buildLevelSelect() {
 	w.currentBoard = ""
-	w.setStatus("Nurikabe - " + w.currentDifficulty[2:])
+	w.setStatus("Nurikabe - " + pack.Name(w.currentDifficulty))
 	w.qGameGrid().Set("spacing", 15)
 	w.qToolBtn().Set("text", "Back")
 	w.qGameGrid().Set("columns", 4)
 
-	names := files(levelDir + w.currentDifficulty)
+	names := files(w.levelDir() + w.currentDifficulty)
 	w.objs = make([]qml.Object, len(names), len(names))
 	for i, name := range names {
-		_, ok := w.records.Level(w.currentDifficulty, levelInt(name))
+		rec, ok := w.records.Level(w.currentDifficulty, levelInt(name))
 		w.objs[i] = w.btnComponent.Create(nil)
 		w.objs[i].Set("parent", w.qGameGrid())
 		w.objs[i].Set("text", levelStr(name)) //remove '.json' from name
 		w.objs[i].Set("data", name)
 		w.objs[i].Set("showstar", true)
-		w.objs[i].Set("completed", ok)
-		w.objs[i].Set("width", 50)
+		w.objs[i].Set("completed", ok && rec.Solved())
+		w.objs[i].Set("thumbnail", w.thumbnailSource(w.levelDir()+w.currentDifficulty+"/"+name))
+		w.objs[i].Set("width", 55)
+		w.objs[i].Set("height", 90)
 	}
 }
 
@@ -281,12 +726,12 @@ func (w *window) buildDifficultySelect() {
 	w.qGameGrid().Set("columns", 1)
 	w.qToolBtn().Set("text", "Menu")
 
-	names := dirs(levelDir)
+	names := dirs(w.levelDir())
 	w.objs = make([]qml.Object, len(names), len(names))
 	for i, name := range names {
 		w.objs[i] = w.btnComponent.Create(nil)
 		w.objs[i].Set("parent", w.qGameGrid())
-		w.objs[i].Set("text", name[2:])
+		w.objs[i].Set("text", pack.Name(name))
 		w.objs[i].Set("data", name)
 		w.objs[i].Set("alignCenter", true)
 		w.objs[i].Set("width", w.winComponent.Root().Int("width")-150)
@@ -308,13 +753,26 @@ func (w *window) buildRules() {
 
 func (w *window) buildStats() {
 	w.currentBoard = ""
-	w.setStatus("Nurikabe - Records")
+	w.setStatus("Nurikabe - " + w.profile.Name + " Records (" + w.records.Policy().Name() + ")")
 	w.qGameGrid().Set("spacing", 7)
 	w.qToolBtn().Set("text", "Back")
 	w.qGameGrid().Set("columns", 4)
 
 	l := w.records.Length()
-	w.objs = make([]qml.Object, 0, l+1)
+	w.objs = make([]qml.Object, 0, l+len(stats.Policies))
+
+	for _, p := range stats.Policies {
+		obj := w.btnComponent.Create(nil)
+		obj.Set("parent", w.qGameGrid())
+		obj.Set("text", p.Name())
+		obj.Set("data", p.Name())
+		obj.Set("alignCenter", true)
+		if p == w.records.Policy() {
+			obj.Set("color", w.theme.Selected)
+		}
+		obj.Set("width", 65)
+		w.objs = append(w.objs, obj)
+	}
 
 	buildTxtBox := func(s string) {
 		obj := w.txtComponent.Create(nil)
@@ -332,30 +790,210 @@ func (w *window) buildStats() {
 	}
 
 	for _, rec := range w.records.All() {
-		buildTxtBox(rec.Difficulty[2:])
+		best, ok := rec.Best(w.records.Policy())
+		if !ok {
+			continue
+		}
+		buildTxtBox(pack.Name(rec.Difficulty))
 		buildTxtBox(strconv.Itoa(rec.Lvl))
-		buildTxtBox(strconv.Itoa(rec.Steps))
-		buildTxtBox(strconv.Itoa(rec.Seconds))
+		buildTxtBox(strconv.Itoa(best.Steps))
+		buildTxtBox(strconv.Itoa(best.Seconds))
+	}
+}
+
+func (w *window) buildProfileSelect() {
+	w.setStatus("Nurikabe - Select Profile")
+	w.qGameGrid().Set("spacing", 15)
+	w.qGameGrid().Set("columns", 1)
+	w.qToolBtn().Set("text", "Menu")
+
+	names, err := profile.List(profileDir)
+	if err != nil {
+		fmt.Println("Error listing profiles", err)
+	}
+	names = append(names, newProfile)
+	w.objs = make([]qml.Object, len(names), len(names))
+	for i, name := range names {
+		w.objs[i] = w.btnComponent.Create(nil)
+		w.objs[i].Set("parent", w.qGameGrid())
+		w.objs[i].Set("text", name)
+		w.objs[i].Set("data", name)
+		w.objs[i].Set("alignCenter", true)
+		if name == w.profile.Name {
+			w.objs[i].Set("color", w.theme.Selected)
+		}
+		w.objs[i].Set("width", w.winComponent.Root().Int("width")-150)
+	}
+}
+
+func (w *window) buildProfileInput() {
+	w.clearGrid()
+	w.setStatus("Nurikabe - New Profile")
+
+	w.objs = make([]qml.Object, 1, 1)
+	w.objs[0] = w.inputComponent.Create(nil)
+	w.objs[0].Set("parent", w.qGameGrid())
+	w.objs[0].Set("placeholderText", "name")
+	w.objs[0].Set("width", w.winComponent.Root().Int("width")-150)
+	w.objs[0].Set("focus", true)
+	w.typing = true
+}
+
+func (w *window) switchProfile(name string) {
+	p, err := profile.Open(profileDir, name)
+	if err != nil {
+		fmt.Println("Error opening profile", err)
+		w.setGameMode(profileSelect)
+		return
+	}
+	w.saveProfile()
+	w.profile = p
+	if err := profile.SetCurrent(profileDir, name); err != nil {
+		fmt.Println("Error saving profile", err)
+	}
+	w.loadStats()
+	w.applyTheme()
+	w.setGameMode(mainMenu)
+	w.checkLevelDir()
+}
+
+func (w *window) saveProfile() {
+	if err := w.records.Save(w.profile.Path(statsFile)); err != nil {
+		fmt.Println("Error saving stats", err)
+	}
+	if err := w.profile.Save(); err != nil {
+		fmt.Println("Error saving profile", err)
+	}
+}
+
+// saveKey identifies the current level in the profile's saved games.
+func (w *window) saveKey() string {
+	return w.currentDifficulty + "/" + w.currentBoard
+}
+
+// saveGame stores the board in progress so it can be resumed later.
+func (w *window) saveGame() {
+	if w.qStatus().Bool("finished") || w.currentDifficulty == endlessDifficulty {
+		return
+	}
+	save := &profile.Save{
+		Rows:    w.g.Rows(),
+		Cols:    w.g.Columns(),
+		Hash:    w.g.Hash(),
+		Steps:   w.qStepsText().Int("moves"),
+		Seconds: w.clock.seconds(),
+		Hints:   w.hints,
+	}
+	for i := 0; i < w.g.Rows()*w.g.Columns(); i++ {
+		switch w.mark(i) {
+		case markClosed:
+			save.Closed = append(save.Closed, i)
+		case markDot:
+			save.Dots = append(save.Dots, i)
+		}
+	}
+	w.profile.Saves[w.saveKey()] = save
+}
+
+// savedOn returns true if save was made on g. Saves from before the grid was
+// stored are only checked to fit on g.
+func savedOn(save *profile.Save, g *grid.Grid) bool {
+	if save.Hash != "" && (save.Rows != g.Rows() || save.Cols != g.Columns() || save.Hash != g.Hash()) {
+		return false
+	}
+	l := g.Rows() * g.Columns()
+	for _, tiles := range [][]int{save.Closed, save.Dots} {
+		for _, i := range tiles {
+			if i < 0 || i >= l {
+				return false
+			}
+		}
 	}
+	return true
 }
 
 func (w *window) loadStats() {
 	var err error
-	d := dirs(levelDir)
+	d := dirs(w.levelDir())
 	sorter := make(map[string]int, len(d))
-	for _, f := range dirs(levelDir) {
-		sorter[f[2:]] = int(f[0] - '0')
-
+	for _, f := range d {
+		order, name, _ := pack.Parse(f)
+		sorter[name] = order
+	}
+	w.records, err = stats.Load(w.profile.Path(statsFile), sorter)
+	if err != nil && w.profile.Name == profile.Default {
+		w.records, err = stats.Load(legacyStatsFile, sorter)
 	}
-	w.records, err = stats.Load(statsFile, sorter)
 	if err != nil {
 		fmt.Println("Error loading stats", err)
 		w.records = stats.New(sorter)
 	}
+	if p, ok := stats.PolicyByName(w.profile.Settings.Policy); ok {
+		w.records.SetPolicy(p)
+	}
 }
 
+func (w *window) addAction(text, data string) {
+	obj := w.actionComponent.Create(nil)
+	obj.Set("parent", w.qActions())
+	obj.Set("text", text)
+	obj.Set("data", data)
+	w.actions = append(w.actions, obj)
+}
+
+func (w *window) clearActions() {
+	for _, obj := range w.actions {
+		obj.Set("visible", false)
+		obj.Destroy()
+	}
+	w.actions = nil
+}
+
+// setTimer starts or stops the clock of the current game. A stopped game is
+// finished and no longer accepts moves.
 func (w *window) setTimer(running bool) {
 	w.qStatus().Set("finished", !running)
+	if running {
+		w.clock.resume()
+	} else {
+		w.clock.pause()
+	}
+	w.qTimeText().Set("seconds", w.clock.seconds())
+}
+
+// Seconds returns the play time of the current game, shown by qml/window.qml.
+func (w *window) Seconds() int {
+	return w.clock.seconds()
+}
+
+// pause stops the clock and hides the board until resume is called.
+func (w *window) pause() {
+	if w.currentMode != nurikabePage || w.paused || w.qStatus().Bool("finished") {
+		return
+	}
+	w.paused = true
+	w.clock.pause()
+	w.qGameGrid().Set("visible", false)
+	w.setStatus("Nurikabe - Paused")
+	w.clearActions()
+	w.addAction("Resume", actionResume)
+}
+
+func (w *window) resume() {
+	if !w.paused {
+		return
+	}
+	w.paused = false
+	w.clock.resume()
+	w.qGameGrid().Set("visible", true)
+	w.setStatus("Nurikabe - " + w.levelTitle())
+	w.clearActions()
+	w.addPlayActions()
+}
+
+// FocusLost pauses the game when the window is deactivated or minimized.
+func (w *window) FocusLost() {
+	w.pause()
 }
 
 func (w *window) setStatus(s string) {
@@ -386,6 +1024,10 @@ func (w *window) qToolBtn() qml.Object {
 	return w.obj("toolBtn")
 }
 
+func (w *window) qActions() qml.Object {
+	return w.obj("actions")
+}
+
 func (w *window) qStepsText() qml.Object {
 	return w.obj("movesText")
 }
@@ -400,6 +1042,8 @@ func (w *window) qRecordText() qml.Object {
 
 func RunNurikabe(engine *qml.Engine) error {
 	context := engine.Context()
+	context.SetVar("theme", draw.Themes[0])
+	engine.AddImageProvider(thumbnailProvider, thumbnail)
 
 	window, err := NewMainWindow(engine)
 	if err != nil {
@@ -407,11 +1051,21 @@ func RunNurikabe(engine *qml.Engine) error {
 	}
 
 	context.SetVar("window", window)
+	window.context = context
+	window.profile, err = profile.Open(profileDir, profile.Current(profileDir))
+	if err != nil {
+		return err
+	}
 	window.loadStats()
+	window.applyTheme()
 	window.setGameMode(mainMenu)
+	window.checkLevelDir()
 
 	window.winComponent.Show()
 	window.winComponent.Wait()
-	window.records.Save(statsFile)
+	if window.currentMode == nurikabePage {
+		window.saveGame()
+	}
+	window.saveProfile()
 	return nil
 }
@@ -0,0 +1,143 @@
+// Package pack reads the layout of a levels directory. It holds a directory
+// for each pack, named after its place and name like 1-easy, and every pack
+// holds its levels as json files numbered from 1, like 3.json.
+package pack
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parse returns the place and shown name of a pack directory like "1-easy",
+// and false if name is not numbered.
+func Parse(name string) (int, string, bool) {
+	i := strings.Index(name, "-")
+	if i < 1 {
+		return 0, name, false
+	}
+	n, err := strconv.Atoi(name[:i])
+	if err != nil || n < 0 {
+		return 0, name, false
+	}
+	return n, name[i+1:], true
+}
+
+// Name returns the shown name of a pack, "easy" for "1-easy". Packs that are
+// not numbered are shown as they are.
+func Name(name string) string {
+	_, n, _ := Parse(name)
+	return n
+}
+
+// Less returns true if pack a comes before pack b, by number and then by
+// name. Packs that are not numbered come last.
+func Less(a, b string) bool {
+	na, _, oka := Parse(a)
+	nb, _, okb := Parse(b)
+	switch {
+	case oka != okb:
+		return oka
+	case na != nb:
+		return na < nb
+	}
+	return a < b
+}
+
+// Dirs returns the packs in root in order. Directories that are not numbered
+// like packs are left out.
+func Dirs(root string) ([]string, error) {
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		if _, _, ok := Parse(f.Name()); ok && f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return Less(names[i], names[j]) })
+	return names, nil
+}
+
+// NewName returns the directory for a pack called name, placed after packs.
+func NewName(packs []string, name string) string {
+	last := 0
+	for _, p := range packs {
+		if n, _, ok := Parse(p); ok && n > last {
+			last = n
+		}
+	}
+	return strconv.Itoa(last+1) + "-" + name
+}
+
+// Check returns an error unless root holds at least one pack.
+func Check(root string) error {
+	names, err := Dirs(root)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return errors.New("no level packs in " + root)
+	}
+	return nil
+}
+
+// Level returns the number of a level file like "3.json", and false if name
+// is not a numbered json file. Images drawn from levels, like 3.svg, are not
+// levels.
+func Level(name string) (int, bool) {
+	if filepath.Ext(name) != ".json" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// Levels returns the level files of the pack dir in level order. Files that
+// are not numbered json files are left out.
+func Levels(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		if _, ok := Level(f.Name()); ok && !f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, _ := Level(names[i])
+		b, _ := Level(names[j])
+		return a < b
+	})
+	return names, nil
+}
+
+// Add writes level, a grid in json, as the next numbered level of the pack
+// dir, creating the pack if needed. Returns the new level file name.
+func Add(dir string, level []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	names, err := Levels(dir)
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	if len(names) > 0 {
+		last, _ := Level(names[len(names)-1])
+		next = last + 1
+	}
+	name := strconv.Itoa(next) + ".json"
+	return name, ioutil.WriteFile(filepath.Join(dir, name), level, 0600)
+}
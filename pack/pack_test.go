@@ -0,0 +1,133 @@
+package pack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type parseTest struct {
+	dir   string
+	order int
+	name  string
+	ok    bool
+}
+
+var parseTests = []*parseTest{
+	&parseTest{"1-easy", 1, "easy", true},
+	&parseTest{"10-my-pack", 10, "my-pack", true},
+	&parseTest{"0-", 0, "", true},
+	&parseTest{"easy", 0, "easy", false},
+	&parseTest{"-easy", 0, "-easy", false},
+	&parseTest{"x-easy", 0, "x-easy", false},
+	&parseTest{"a", 0, "a", false},
+}
+
+func TestParse(t *testing.T) {
+	for _, p := range parseTests {
+		order, name, ok := Parse(p.dir)
+		if order != p.order || name != p.name || ok != p.ok {
+			t.Fatal("Invalid parse", order, name, ok, p)
+		}
+		if Name(p.dir) != p.name {
+			t.Fatal("Invalid name", Name(p.dir), p)
+		}
+	}
+}
+
+func TestDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, d := range []string{"10-ten", "2-medium", "1-easy", "a", "notes"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "3-file"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := Dirs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1-easy", "2-medium", "10-ten"}; !reflect.DeepEqual(names, want) {
+		t.Fatal("Invalid dirs", names)
+	}
+	if n := NewName(names, "mine"); n != "11-mine" {
+		t.Fatal("Invalid new name", n)
+	}
+	if n := NewName(nil, "mine"); n != "1-mine" {
+		t.Fatal("Invalid first name", n)
+	}
+	if _, err := Dirs(filepath.Join(root, "missing")); err == nil {
+		t.Fatal("Missing root read")
+	}
+	if err := Check(root); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(filepath.Join(root, "missing")); err == nil {
+		t.Fatal("Missing root checked")
+	}
+}
+
+func TestLevels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, f := range []string{"10.json", "2.json", "1.svg", "1.png", "README", "3.json.tmp", "0.json", "x.json"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "4"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := Levels(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2.json", "10.json"}; !reflect.DeepEqual(names, want) {
+		t.Fatal("Invalid levels", names)
+	}
+	if _, ok := Level("1.svg"); ok {
+		t.Fatal("Image read as a level")
+	}
+	if err := Check(dir); err == nil {
+		t.Fatal("Pack checked as a levels directory")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	root, err := ioutil.TempDir("", "pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	dir := filepath.Join(root, "1-mine")
+	for _, want := range []string{"1.json", "2.json"} {
+		name, err := Add(dir, []byte("{}\n"))
+		if err != nil || name != want {
+			t.Fatal("Invalid level added", name, err)
+		}
+	}
+	for _, f := range []string{"9.json", "12.svg"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if name, err := Add(dir, []byte("{}\n")); err != nil || name != "10.json" {
+		t.Fatal("Invalid level after 9", name, err)
+	}
+	if dat, err := ioutil.ReadFile(filepath.Join(dir, "1.json")); err != nil || string(dat) != "{}\n" {
+		t.Fatal("Invalid level file", string(dat), err)
+	}
+}
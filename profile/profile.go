@@ -0,0 +1,192 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Default = "default"
+
+	savesFile          = "saves.json"
+	configFile         = "config.json"
+	legacySettingsFile = "settings.json"
+	currentFile        = "current"
+)
+
+// ConfigVersion is the version of the settings written to config.json.
+// Version 0 settings were kept in settings.json and are migrated on Open.
+const ConfigVersion = 1
+
+// Assist levels highlight more and more rule violations during play.
+const (
+	AssistOff      = iota
+	AssistMistakes // only violations that more walls can not fix
+	AssistAll
+)
+
+// Profile is a single player's directory of records, saved games and settings.
+type Profile struct {
+	Name     string
+	Saves    map[string]*Save
+	Settings *Settings
+	dir      string
+}
+
+// Save is an unfinished game that can be resumed later. Rows, Cols and Hash
+// name the grid it was played on, so a changed level does not resume it.
+type Save struct {
+	Rows    int    `json:"rows,omitempty"`
+	Cols    int    `json:"cols,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+	Closed  []int  `json:"closed,omitempty"`
+	Dots    []int  `json:"dots,omitempty"`
+	Steps   int    `json:"steps,omitempty"`
+	Seconds int    `json:"seconds,omitempty"`
+	Hints   int    `json:"hints,omitempty"`
+}
+
+// Settings are the preferences of a profile. Zero values are the defaults.
+type Settings struct {
+	Version   int    `json:"version"`
+	Policy    string `json:"policy,omitempty"`
+	Assist    int    `json:"assist,omitempty"`
+	Theme     string `json:"theme,omitempty"`
+	Cycle     string `json:"cycle,omitempty"`     // order of marks a click steps through
+	SwapClick bool   `json:"swapClick,omitempty"` // right click places walls
+	AutoDot   bool   `json:"autoDot,omitempty"`   // dot islands once they are complete
+	HideTimer bool   `json:"hideTimer,omitempty"`
+	LevelDir  string `json:"levelDir,omitempty"`
+}
+
+// legacySettings is the version 0 settings file.
+type legacySettings struct {
+	Policy string `json:"policy,omitempty"`
+	Assist bool   `json:"assist,omitempty"`
+	Theme  string `json:"theme,omitempty"`
+}
+
+// List returns the names of all profiles under root, sorted.
+func List(root string) ([]string, error) {
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Valid returns true if name can be used as a profile directory.
+func Valid(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// Open loads the profile name under root, creating it if it does not exist.
+func Open(root, name string) (*Profile, error) {
+	p := &Profile{
+		Name:     name,
+		Saves:    make(map[string]*Save),
+		Settings: &Settings{},
+		dir:      filepath.Join(root, name),
+	}
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := p.load(savesFile, &p.Saves); err != nil {
+		return nil, err
+	}
+	if err := p.loadSettings(); err != nil {
+		return nil, err
+	}
+	if p.Saves == nil {
+		p.Saves = make(map[string]*Save)
+	}
+	return p, nil
+}
+
+// Current returns the name of the last selected profile under root.
+func Current(root string) string {
+	dat, err := ioutil.ReadFile(filepath.Join(root, currentFile))
+	if name := strings.TrimSpace(string(dat)); err == nil && Valid(name) {
+		return name
+	}
+	return Default
+}
+
+func SetCurrent(root, name string) error {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(root, currentFile), []byte(name+"\n"), 0600)
+}
+
+// Path returns the location of file inside the profile directory.
+func (p *Profile) Path(file string) string {
+	return filepath.Join(p.dir, file)
+}
+
+// Save writes the saved games and settings of the profile.
+func (p *Profile) Save() error {
+	if err := p.write(savesFile, p.Saves); err != nil {
+		return err
+	}
+	return p.write(configFile, p.Settings)
+}
+
+func (p *Profile) loadSettings() error {
+	if err := p.load(configFile, p.Settings); err != nil {
+		return err
+	}
+	if p.Settings.Version > ConfigVersion {
+		return errors.New(p.Path(configFile) + ": unsupported version " + strconv.Itoa(p.Settings.Version))
+	}
+	if p.Settings.Version == 0 {
+		var old legacySettings
+		if err := p.load(legacySettingsFile, &old); err != nil {
+			return err
+		}
+		p.Settings.Policy = old.Policy
+		p.Settings.Theme = old.Theme
+		if old.Assist {
+			p.Settings.Assist = AssistAll
+		}
+	}
+	if p.Settings.Assist < AssistOff || p.Settings.Assist > AssistAll {
+		p.Settings.Assist = AssistOff
+	}
+	p.Settings.Version = ConfigVersion
+	return nil
+}
+
+func (p *Profile) load(file string, v interface{}) error {
+	dat, err := ioutil.ReadFile(p.Path(file))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(dat, v)
+}
+
+func (p *Profile) write(file string, v interface{}) error {
+	dat, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.Path(file), dat, 0600)
+}
@@ -0,0 +1,45 @@
+package profile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	root, err := ioutil.TempDir("", "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(filepath.Join(root, "old"), 0700)
+	legacy := `{"policy":"time","assist":true,"theme":"dark"}`
+	if err := ioutil.WriteFile(filepath.Join(root, "old", legacySettingsFile), []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Open(root, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Settings; s.Version != ConfigVersion || s.Policy != "time" || s.Assist != AssistAll || s.Theme != "dark" {
+		t.Fatal("Invalid migrated settings", s)
+	}
+
+	p.Settings.AutoDot = true
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = Open(root, "old"); err != nil || !p.Settings.AutoDot || p.Settings.Policy != "time" {
+		t.Fatal("Settings not saved", p.Settings, err)
+	}
+
+	newer := `{"version":99}`
+	if err := ioutil.WriteFile(p.Path(configFile), []byte(newer), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(root, "old"); err == nil {
+		t.Fatal("Opened newer config")
+	}
+}
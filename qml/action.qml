@@ -0,0 +1,7 @@
+import QtQuick 2.0
+import QtQuick.Controls 1.0
+
+Button {
+    property string data
+    onClicked: window.onActionClicked(data)
+}
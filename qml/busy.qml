@@ -0,0 +1,6 @@
+import QtQuick 2.0
+import QtQuick.Controls 1.3
+
+BusyIndicator {
+    running: visible
+}
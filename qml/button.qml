@@ -4,27 +4,30 @@ import QtQuick.Controls.Styles 1.0
 
 Button {
     onClicked: window.onBtnClicked(data)
-    property string color: "lightsteelblue"
+    property string color: theme.button
     property string data
     property bool completed: false
     property bool showstar: false
     property bool alignCenter: false
+    property bool selected: false
+    property string thumbnail: ""
 
     style: ButtonStyle {
         label: Text {
             renderType: Text.NativeRendering
-            font.pointSize: 20
-            color: "black"
+            font.pointSize: control.thumbnail ? 14 : 20
+            color: theme.buttonText
             text: control.text
 
-            verticalAlignment: Text.AlignVCenter
+            verticalAlignment: control.thumbnail ? Text.AlignBottom : Text.AlignVCenter
             horizontalAlignment: control.alignCenter ? Text.AlignHCenter : Text.AlignLeft
             anchors.fill: parent
         }
 
         background: Component {
             Rectangle {
-                border.width: 1
+                border.width: control.selected ? 3 : 1
+                border.color: theme.border
                 radius: 5
                 gradient: Gradient {
                     GradientStop { position: 0 ; color: control.pressed ? Qt.darker(control.color) : control.color }
@@ -32,9 +35,23 @@ Button {
                 }
                 anchors.fill: parent
 
+                Image {
+                    anchors.top: parent.top
+                    anchors.horizontalCenter: parent.horizontalCenter
+                    anchors.topMargin: 5
+                    width: parent.width - 10
+                    height: width
+                    fillMode: Image.PreserveAspectFit
+                    asynchronous: true
+                    source: control.thumbnail
+                    visible: control.thumbnail != ""
+                }
+
                 Image {
                     anchors.right: parent.right
-                    anchors.verticalCenter: parent.verticalCenter
+                    anchors.verticalCenter: control.thumbnail ? undefined : parent.verticalCenter
+                    anchors.bottom: control.thumbnail ? parent.bottom : undefined
+                    anchors.bottomMargin: 5
                     width: 20
                     height: 20
                     source: control.completed ? "images/star.png" : "images/emptystar.png"
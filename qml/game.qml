@@ -2,18 +2,165 @@ import QtQuick 2.2
 import QtQuick.Layouts 1.1
 
 Rectangle {
-    Flickable {
-        clip: true
-        boundsBehavior: Flickable.StopAtBounds
-        anchors.centerIn: parent
-        width: { return Math.min(parent.width, g.width) }
-        height: { return Math.min(parent.height, g.height) }
-        contentWidth: g.width; contentHeight: g.height
-        flickableDirection: Flickable.HorizontalAndVerticalFlick
-        Grid {
-            id: g
-            objectName: "grid"
-            spacing: 1
+    id: page
+    focus: true
+    color: theme.background
+    Keys.onPressed: {
+        var cmd = ""
+        var ctrl = event.modifiers & Qt.ControlModifier
+        switch (event.key) {
+        case Qt.Key_Left: cmd = "left"; break
+        case Qt.Key_Right: cmd = "right"; break
+        case Qt.Key_Up: cmd = "up"; break
+        case Qt.Key_Down: cmd = "down"; break
+        case Qt.Key_Space:
+        case Qt.Key_Return:
+        case Qt.Key_Enter: cmd = "toggle"; break
+        case Qt.Key_Period: cmd = "dot"; break
+        case Qt.Key_H: cmd = "hint"; break
+        case Qt.Key_P: cmd = "pause"; break
+        case Qt.Key_R: cmd = "rotate"; break
+        case Qt.Key_F: cmd = "flip"; break
+        case Qt.Key_Z:
+            if (ctrl) cmd = event.modifiers & Qt.ShiftModifier ? "redo" : "undo"
+            break
+        case Qt.Key_Y:
+            if (ctrl) cmd = "redo"
+            break
+        case Qt.Key_Escape:
+        case Qt.Key_Backspace: cmd = "back"; break
+        case Qt.Key_Plus:
+        case Qt.Key_Equal:
+            g.zoomBy(1.25)
+            event.accepted = true
+            return
+        case Qt.Key_Minus:
+            g.zoomBy(0.8)
+            event.accepted = true
+            return
+        case Qt.Key_0:
+            g.zoom = 1
+            event.accepted = true
+            return
+        }
+        if (cmd != "") {
+            window.keyCommand(cmd)
+            event.accepted = true
+        }
+    }
+
+    PinchArea {
+        anchors.fill: parent
+        property real startZoom: 1
+        onPinchStarted: startZoom = g.zoom
+        onPinchUpdated: g.setZoom(startZoom * pinch.scale)
+
+        Flickable {
+            id: flick
+            clip: true
+            boundsBehavior: Flickable.StopAtBounds
+            anchors.centerIn: parent
+            width: { return Math.min(parent.width, content.width) }
+            height: { return Math.min(parent.height, content.height) }
+            contentWidth: content.width; contentHeight: content.height
+            flickableDirection: Flickable.HorizontalAndVerticalFlick
+
+            Item {
+                id: content
+                width: g.x + g.width
+                height: g.y + g.height
+
+                // column and row numbers of boards
+                Row {
+                    x: g.x
+                    spacing: g.spacing
+                    visible: g.board
+                    Repeater {
+                        model: g.board ? g.columns : 0
+                        Text {
+                            width: g.tileSize
+                            height: g.labelSize
+                            horizontalAlignment: Text.AlignHCenter
+                            verticalAlignment: Text.AlignVCenter
+                            font.pixelSize: Math.min(g.labelSize, g.tileSize) * 0.6
+                            color: theme.text
+                            text: index + 1
+                        }
+                    }
+                }
+                Column {
+                    y: g.y
+                    spacing: g.spacing
+                    visible: g.board
+                    Repeater {
+                        model: g.board ? g.rows : 0
+                        Text {
+                            width: g.labelSize
+                            height: g.tileSize
+                            horizontalAlignment: Text.AlignHCenter
+                            verticalAlignment: Text.AlignVCenter
+                            font.pixelSize: Math.min(g.labelSize, g.tileSize) * 0.6
+                            color: theme.text
+                            text: index + 1
+                        }
+                    }
+                }
+
+                Grid {
+                    id: g
+                    objectName: "grid"
+                    spacing: 1
+                    x: board ? labelSize : 0
+                    y: board ? labelSize : 0
+
+                    // rows is only set for boards of tiles
+                    property bool board: rows > 0
+                    property int labelSize: 20
+                    property int minTile: 20
+                    property real zoom: 1
+                    property real fit: board ? Math.min((page.width - labelSize) / columns,
+                                                        (page.height - labelSize) / rows) - spacing : 40
+                    property int tileSize: Math.max(minTile, Math.floor(fit * zoom))
+
+                    function setZoom(z) {
+                        zoom = Math.max(0.25, Math.min(z, 8))
+                    }
+
+                    function zoomBy(f) {
+                        setZoom(zoom * f)
+                    }
+
+                    // reveal scrolls tile t into view
+                    function reveal(t) {
+                        var tx = g.x + t.x, ty = g.y + t.y
+                        if (tx < flick.contentX) flick.contentX = tx
+                        else if (tx + t.width > flick.contentX + flick.width) flick.contentX = tx + t.width - flick.width
+                        if (ty < flick.contentY) flick.contentY = ty
+                        else if (ty + t.height > flick.contentY + flick.height) flick.contentY = ty + t.height - flick.height
+                    }
+                }
+
+                // middle button drag pans, ctrl+wheel zooms
+                MouseArea {
+                    anchors.fill: parent
+                    acceptedButtons: Qt.MiddleButton
+                    property point last
+                    onPressed: last = Qt.point(mouse.x - flick.contentX, mouse.y - flick.contentY)
+                    onPositionChanged: {
+                        var px = mouse.x - flick.contentX, py = mouse.y - flick.contentY
+                        flick.contentX = Math.max(0, Math.min(flick.contentX - (px - last.x), flick.contentWidth - flick.width))
+                        flick.contentY = Math.max(0, Math.min(flick.contentY - (py - last.y), flick.contentHeight - flick.height))
+                        last = Qt.point(px, py)
+                    }
+                    onWheel: {
+                        if (wheel.modifiers & Qt.ControlModifier) {
+                            g.zoomBy(wheel.angleDelta.y > 0 ? 1.25 : 0.8)
+                        } else {
+                            wheel.accepted = false
+                        }
+                    }
+                }
+            }
         }
     }
 }
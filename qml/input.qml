@@ -0,0 +1,7 @@
+import QtQuick 2.0
+import QtQuick.Controls 1.0
+
+TextField {
+    font.pointSize: 20
+    onAccepted: window.onInputAccepted(text)
+}
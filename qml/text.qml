@@ -4,6 +4,7 @@ Text {
     width: 65
     wrapMode: Text.WordWrap
     font.pixelSize: 16
+    color: theme.text
     verticalAlignment: Text.AlignVCenter
     horizontalAlignment: Text.AlignHCenter
 }
@@ -3,23 +3,43 @@ import QtQuick 2.0
 Rectangle {
     id: tile
     state: "open"
-    width: 40
-    height: 40
+    width: parent ? parent.tileSize : 40
+    height: width
     border.width: 5
-    border.color: "black"
-    color: "white"
+    border.color: cursor ? theme.cursor : theme.border
+    color: theme.tile
 
     property int count: 0
     property int index: 0
+    property bool editing: false
+    property bool cursor: false
+
+    onCursorChanged: if (cursor) parent.reveal(tile)
 
     states: [
         State {
             name: "open"
-            PropertyChanges { target: tile; color: "white" }
+            PropertyChanges { target: tile; color: theme.tile }
         },
         State {
             name: "closed"
-            PropertyChanges { target: tile; color: "black" }
+            PropertyChanges { target: tile; color: theme.wall }
+        },
+        State {
+            name: "dot"
+            PropertyChanges { target: tile; color: theme.tile }
+        },
+        State {
+            name: "openError"
+            PropertyChanges { target: tile; color: theme.error }
+        },
+        State {
+            name: "dotError"
+            PropertyChanges { target: tile; color: theme.error }
+        },
+        State {
+            name: "closedError"
+            PropertyChanges { target: tile; color: theme.wallError }
         }
     ]
 
@@ -30,19 +50,58 @@ Rectangle {
     Text {
         anchors.centerIn: parent
         font.pixelSize: parent.width / 3
-        color: "black"
+        color: theme.tileText
         visible: count > 0
         text: count
     }
 
+    Rectangle {
+        anchors.centerIn: parent
+        width: parent.width / 5
+        height: width
+        radius: width / 2
+        color: theme.tileText
+        visible: tile.state.indexOf("dot") == 0
+    }
+
     MouseArea {
         id: mouseArea
         anchors.fill: parent
         acceptedButtons: Qt.LeftButton | Qt.RightButton
-        onClicked: {
-            if (count > 0) return
-            tile.state = tile.state == "open" ? "closed" : "open"
-            window.tileChecked(index);
+        preventStealing: true
+
+        // tiles crossed while dragging, painted like the first one
+        property var painted: []
+        property bool right: false
+        property string paint: ""
+
+        onPressed: {
+            right = mouse.button == Qt.RightButton
+            painted = [index]
+            if (!editing && count == 0) paint = window.paintState(index, right)
+        }
+        onPositionChanged: {
+            if (editing || count > 0) return
+            var p = mapToItem(tile.parent, mouse.x, mouse.y)
+            var t = tile.parent.childAt(p.x, p.y)
+            if (t && t.count == 0 && painted.indexOf(t.index) == -1) {
+                painted.push(t.index)
+                t.state = paint
+            }
+        }
+        onReleased: {
+            if (painted.length > 1) {
+                window.tilesChecked(painted, right)
+            } else if (mouse.x >= 0 && mouse.y >= 0 && mouse.x < width && mouse.y < height) {
+                if (editing) {
+                    window.tileEdited(index, right ? -1 : 1)
+                } else if (right) {
+                    window.tileDotted(index)
+                } else {
+                    window.tileChecked(index)
+                }
+            }
+            painted = []
         }
     }
 }
@@ -1,12 +1,15 @@
 import QtQuick 2.2
 import QtQuick.Controls 1.0
 import QtQuick.Layouts 1.1
+import QtQuick.Window 2.1
 
 ApplicationWindow {
     objectName: "mainwindow"
     width: 300
     height: 350
-    color: "white"
+    color: theme.background
+    onActiveChanged: if (!active) window.focusLost()
+    onVisibilityChanged: if (visibility == Window.Minimized) window.focusLost()
     ColumnLayout {
         anchors.fill: parent
         spacing: 0
@@ -14,9 +17,11 @@ ApplicationWindow {
         Rectangle {
             Layout.fillWidth: true
             Layout.preferredHeight: statusText.height + 10
-            border.color: "black"
+            color: theme.background
+            border.color: theme.border
             border.width: 1
             Text {
+                color: theme.text
                 id: statusText
                 property bool finished: false
                 anchors {
@@ -28,6 +33,7 @@ ApplicationWindow {
                 text: "Nurikabe"
             }
             Text {
+                color: theme.text
                 property int moves: 0
                 anchors {
                     verticalCenter: parent.verticalCenter
@@ -41,13 +47,13 @@ ApplicationWindow {
                 }
             }
             Text {
+                color: theme.text
                 Timer {
                     interval: 200;  repeat: true
                     running: !statusText.finished
-                    onTriggered: timerText.seconds = Math.floor((new Date().getTime() - timerText.start.getTime()) / 1000)
+                    onTriggered: timerText.seconds = window.seconds()
                 }
                 id: timerText
-                property date start: new Date()
                 property int seconds: 0
                 anchors {
                     verticalCenter: parent.verticalCenter
@@ -57,15 +63,12 @@ ApplicationWindow {
                 objectName: "timerText"
                 visible: false
                 text: "time: " + seconds
-                onVisibleChanged: {
-                    timerText.start = new Date()
-                    timerText.seconds = 0
-                }
             }
         }
 
         Loader {
             objectName: "pageLoader"
+            focus: true
             Layout.fillHeight: true
             Layout.fillWidth: true
             source: "game.qml"
@@ -73,7 +76,8 @@ ApplicationWindow {
 
         Rectangle {
             height: statusText.height + 10
-            border.color: "black"
+            color: theme.background
+            border.color: theme.border
             border.width: 1
             Layout.fillWidth: true
             RowLayout{
@@ -85,8 +89,14 @@ ApplicationWindow {
                     onClicked: window.toolButtonClicked()
                 }
 
+                Row {
+                    objectName: "actions"
+                    spacing: 5
+                }
+
                 Text {
                     objectName: "recordText"
+                    color: theme.text
                     anchors.right: parent.right
                     property int moves: 0
                     property int seconds: 0
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ostlerc/nurikabe/stats"
+)
+
+var (
+	file    = flag.String("file", ".profiles/default/stats.json", "records file to read and update")
+	export  = flag.String("export", "", "write all records to stdout as csv or json")
+	imports = flag.String("import", "", "merge records from an exported .csv or .json file")
+	merge   = flag.Bool("merge", false, "merge the records files given as arguments into -file")
+	dryRun  = flag.Bool("n", false, "report import and merge changes without saving")
+)
+
+func init() {
+	flag.Parse()
+}
+
+func main() {
+	recs, err := stats.Load(*file, nil)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Fatal(err)
+		}
+		recs = stats.New(nil)
+	}
+
+	if *imports != "" {
+		r, err := os.Open(*imports)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var other *stats.Records
+		if strings.HasSuffix(*imports, ".csv") {
+			other, err = stats.ReadCSV(r)
+		} else {
+			other, err = stats.ReadJSON(r)
+		}
+		r.Close()
+		if err != nil {
+			log.Fatal(*imports, ": ", err)
+		}
+		changes := stats.Merge(recs, other)
+		fmt.Fprintln(os.Stderr, *imports+":", len(changes), "levels updated")
+		for _, c := range changes {
+			fmt.Fprintln(os.Stderr, "  "+c.String())
+		}
+		if !*dryRun {
+			if err := recs.Save(*file); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	if *merge {
+		if flag.NArg() == 0 {
+			log.Fatal("merge needs at least one records file")
+		}
+		for _, f := range flag.Args() {
+			other, err := stats.Load(f, nil)
+			if err != nil {
+				log.Fatal(f, ": ", err)
+			}
+			changes := stats.Merge(recs, other)
+			fmt.Fprintln(os.Stderr, f+":", len(changes), "levels updated")
+			for _, c := range changes {
+				fmt.Fprintln(os.Stderr, "  "+c.String())
+			}
+		}
+		if !*dryRun {
+			if err := recs.Save(*file); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	switch *export {
+	case "":
+	case "csv":
+		err = recs.WriteCSV(os.Stdout)
+	case "json":
+		err = recs.WriteJSON(os.Stdout)
+	default:
+		log.Fatal("unknown export format ", *export)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ostlerc/nurikabe/draw"
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/pack"
+	"github.com/ostlerc/nurikabe/validator"
+)
+
+var (
+	cell       = flag.Int("cell", draw.DefaultCell, "tile size in pixels")
+	theme      = flag.String("theme", draw.Themes[0].Name, "colors: "+themeNames())
+	solution   = flag.Bool("solution", false, "show the solution of the puzzle")
+	violations = flag.Bool("violations", false, "highlight tiles breaking a rule")
+	format     = flag.String("format", "svg", "image format: svg, png or pdf")
+	title      = flag.String("title", "Nurikabe", "title of pdf booklets")
+	perPage    = flag.Int("per-page", 4, "puzzles on each page of pdf booklets")
+	outDir     = flag.String("o", "", "directory for the images of several level files")
+)
+
+func init() {
+	flag.Parse()
+}
+
+func themeNames() string {
+	var names []string
+	for _, t := range draw.Themes {
+		names = append(names, t.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// main draws the grid on stdin, or the level file given as an argument, to
+// stdout. With -o every level file given is drawn to an image in that
+// directory instead. Pdf booklets hold every level file and pack directory
+// given as an argument and are written to stdout.
+func main() {
+	t, ok := draw.ThemeByName(*theme)
+	if !ok {
+		log.Fatal("unknown theme " + *theme + ", use one of " + themeNames())
+	}
+	switch *format {
+	case "svg", "png":
+	case "pdf":
+		if err := booklet(flag.Args(), os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	default:
+		log.Fatal("unknown format " + *format)
+	}
+	switch {
+	case flag.NArg() == 0:
+		if err := render(os.Stdin, os.Stdout, t); err != nil {
+			log.Fatal(err)
+		}
+		return
+	case *outDir == "" && flag.NArg() > 1:
+		log.Fatal("use -o to draw several level files")
+	case *outDir == "":
+		in, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer in.Close()
+		if err := render(in, os.Stdout, t); err != nil {
+			log.Fatal(flag.Arg(0) + ": " + err.Error())
+		}
+		return
+	}
+	if err := os.MkdirAll(*outDir, 0700); err != nil {
+		log.Fatal(err)
+	}
+	for _, name := range flag.Args() {
+		in, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out, err := os.Create(filepath.Join(*outDir, imageName(name)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = render(in, out, t)
+		in.Close()
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			log.Fatal(name + ": " + err.Error())
+		}
+	}
+}
+
+// booklet writes a pdf booklet of the levels in names, or of the grid on stdin
+// without names. Every level is solved for the answers and its grade.
+func booklet(names []string, out io.Writer) error {
+	var levels []string
+	for _, name := range names {
+		if st, err := os.Stat(name); err == nil && st.IsDir() {
+			files, err := pack.Levels(name)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				levels = append(levels, filepath.Join(name, f))
+			}
+		} else {
+			levels = append(levels, name)
+		}
+	}
+
+	var puzzles []draw.Puzzle
+	add := func(title string, in io.Reader) error {
+		g, _, err := grid.ReadAny(in)
+		if err != nil {
+			return err
+		}
+		p := draw.Puzzle{Title: title, Grid: g}
+		if solutions, grade, _ := validator.Check(g, validator.NewNurikabe(), 1, nil); len(solutions) > 0 {
+			p.Title += " - " + grade.String()
+			p.Solution = solutions[0]
+		}
+		puzzles = append(puzzles, p)
+		return nil
+	}
+	if len(levels) == 0 {
+		if err := add("1", os.Stdin); err != nil {
+			return err
+		}
+	}
+	for _, name := range levels {
+		in, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = add(levelTitle(name), in)
+		in.Close()
+		if err != nil {
+			return errors.New(name + ": " + err.Error())
+		}
+	}
+	return draw.Booklet(out, *title, puzzles, *perPage)
+}
+
+// levelTitle returns the title of a level file in packs named like
+// levels/1-easy/3.json, "easy 3".
+func levelTitle(name string) string {
+	return pack.Name(filepath.Base(filepath.Dir(name))) + " " + strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+}
+
+// imageName returns the file name of the image of a level file, named after
+// its pack when it is in one so levels of several packs do not clash, like
+// easy-3.svg for levels/1-easy/3.json.
+func imageName(name string) string {
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)) + "." + *format
+	if _, p, ok := pack.Parse(filepath.Base(filepath.Dir(name))); ok {
+		return p + "-" + base
+	}
+	return base
+}
+
+// render reads a grid in any format and writes its image in theme t.
+func render(in io.Reader, out io.Writer, t *draw.Theme) error {
+	g, _, err := grid.ReadAny(in)
+	if err != nil {
+		return err
+	}
+	o := draw.Options{
+		Cell:       *cell,
+		Theme:      t,
+		Violations: *violations,
+	}
+	if *solution {
+		solutions := validator.Solutions(g, validator.NewNurikabe(), 1)
+		if len(solutions) == 0 {
+			return errors.New("no solution")
+		}
+		o.Solution = solutions[0]
+	}
+	if *format == "png" {
+		return draw.PNG(out, g, o)
+	}
+	return draw.SVG(out, g, o)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"github.com/ostlerc/nurikabe/draw"
+	"github.com/ostlerc/nurikabe/pack"
+
+	"gopkg.in/qml.v1"
+)
+
+// Settings page buttons, each steps one setting to its next value.
+const (
+	settingTheme     = "theme"
+	settingCycle     = "cycle"
+	settingSwapClick = "swap"
+	settingAutoDot   = "autodot"
+	settingAssist    = "assist"
+	settingTimer     = "timer"
+	settingLevelDir  = "levels"
+)
+
+// assistNames are the assist levels of profile.Settings by value.
+var assistNames = []string{"off", "mistakes", "all"}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (w *window) buildSettings() {
+	w.setStatus("Nurikabe - Settings")
+	w.qGameGrid().Set("spacing", 15)
+	w.qGameGrid().Set("columns", 1)
+	w.qToolBtn().Set("text", "Menu")
+
+	s := w.profile.Settings
+	walls := "left click"
+	if s.SwapClick {
+		walls = "right click"
+	}
+	settings := []struct{ text, data string }{
+		{"Theme: " + w.theme.Name, settingTheme},
+		{"Click: " + cycleByName(s.Cycle).name, settingCycle},
+		{"Walls: " + walls, settingSwapClick},
+		{"Auto dot: " + onOff(s.AutoDot), settingAutoDot},
+		{"Assist: " + assistNames[s.Assist], settingAssist},
+		{"Timer: " + onOff(!s.HideTimer), settingTimer},
+		{"Levels: " + w.levelDir(), settingLevelDir},
+	}
+	w.objs = make([]qml.Object, len(settings), len(settings))
+	for i, s := range settings {
+		w.objs[i] = w.btnComponent.Create(nil)
+		w.objs[i].Set("parent", w.qGameGrid())
+		w.objs[i].Set("text", s.text)
+		w.objs[i].Set("data", s.data)
+		w.objs[i].Set("width", w.winComponent.Root().Int("width")-50)
+	}
+}
+
+func (w *window) buildLevelDirInput() {
+	w.clearGrid()
+	w.setStatus("Nurikabe - Levels Directory")
+
+	w.objs = make([]qml.Object, 1, 1)
+	w.objs[0] = w.inputComponent.Create(nil)
+	w.objs[0].Set("parent", w.qGameGrid())
+	w.objs[0].Set("text", w.levelDir())
+	w.objs[0].Set("width", w.winComponent.Root().Int("width")-50)
+	w.objs[0].Set("focus", true)
+	w.typing = true
+}
+
+// changeSetting moves the setting of a settings page button to its next value.
+func (w *window) changeSetting(data string) {
+	s := w.profile.Settings
+	switch data {
+	case settingTheme:
+		for i, t := range draw.Themes {
+			if t == w.theme {
+				s.Theme = draw.Themes[(i+1)%len(draw.Themes)].Name
+				break
+			}
+		}
+		w.applyTheme()
+	case settingCycle:
+		for i, c := range clickCycles {
+			if c == cycleByName(s.Cycle) {
+				s.Cycle = clickCycles[(i+1)%len(clickCycles)].name
+				break
+			}
+		}
+	case settingSwapClick:
+		s.SwapClick = !s.SwapClick
+	case settingAutoDot:
+		s.AutoDot = !s.AutoDot
+	case settingAssist:
+		s.Assist = (s.Assist + 1) % len(assistNames)
+	case settingTimer:
+		s.HideTimer = !s.HideTimer
+	case settingLevelDir:
+		w.buildLevelDirInput()
+		return
+	}
+	cursor := w.cursor
+	w.setGameMode(settingsPage)
+	if cursor != -1 {
+		w.cursor = cursor
+		w.objs[cursor].Set("selected", true)
+	}
+}
+
+// levelDir returns the directory holding the level packs, ending in a slash.
+func (w *window) levelDir() string {
+	dir := w.profile.Settings.LevelDir
+	if dir == "" {
+		return levelDir
+	}
+	if dir[len(dir)-1] != '/' {
+		dir += "/"
+	}
+	return dir
+}
+
+// setLevelDir makes dir the directory holding the level packs, if it has any.
+func (w *window) setLevelDir(dir string) {
+	if err := pack.Check(dir); err != nil {
+		w.setStatus("Nurikabe - No level packs in " + dir)
+		return
+	}
+	w.saveProfile()
+	w.profile.Settings.LevelDir = dir
+	if w.levelDir() == levelDir {
+		w.profile.Settings.LevelDir = ""
+	}
+	w.loadStats()
+	w.setGameMode(settingsPage)
+}
+
+// checkLevelDir goes back to the default levels when the profile's level
+// directory has no packs any more, like after it was deleted.
+func (w *window) checkLevelDir() {
+	if w.profile.Settings.LevelDir == "" || pack.Check(w.levelDir()) == nil {
+		return
+	}
+	w.profile.Settings.LevelDir = ""
+	w.loadStats()
+	w.setStatus("Nurikabe - Levels not found, using " + levelDir)
+}
+
+// applyTheme makes the profile's theme the one used by the qml files.
+func (w *window) applyTheme() {
+	w.theme = themeByName(w.profile.Settings.Theme)
+	w.context.SetVar("theme", w.theme)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ostlerc/nurikabe/grid"
+	"github.com/ostlerc/nurikabe/stats"
+	"github.com/ostlerc/nurikabe/validator"
+
+	"gopkg.in/qml.v1"
+)
+
+const revealDelay = 40 * time.Millisecond
+
+// withSolution solves the current puzzle off the UI thread and calls f with
+// the solution closest to the board, unless the player left the puzzle first.
+// Puzzles with several solutions are solved again from the walls and dots on
+// the board, so walls that are right in another solution are not mistakes.
+// Boards that no solution agrees with fall back to the first solution.
+func (w *window) withSolution(f func(solution []bool)) {
+	dots := make([]bool, w.g.Rows()*w.g.Columns())
+	for i := range dots {
+		dots[i] = w.g.Dot(i)
+	}
+	if w.solution != nil && (w.uniqueSolution || agrees(w.g, dots, w.solution)) {
+		f(w.solution)
+		return
+	}
+	w.generation++
+	generation := w.generation
+	g := w.g.Copy()
+	first, unique := w.solution, w.uniqueSolution
+	w.setStatus("Nurikabe - Solving")
+	go func() {
+		v := validator.NewNurikabe()
+		if first == nil {
+			solutions := validator.Solutions(g, v, 2)
+			if len(solutions) > 0 {
+				first, unique = solutions[0], len(solutions) == 1
+			}
+		}
+		solution := first
+		if first != nil && !unique && !agrees(g, dots, first) {
+			if solutions := validator.SolutionsFrom(g, v, 1, dots); len(solutions) > 0 {
+				solution = solutions[0]
+			}
+		}
+		qml.Lock()
+		defer qml.Unlock()
+		if generation != w.generation || w.currentMode != nurikabePage {
+			return
+		}
+		if first == nil {
+			w.setStatus("Nurikabe - No solution")
+			return
+		}
+		w.solution = first
+		w.uniqueSolution = unique
+		f(solution)
+	}()
+}
+
+// agrees returns true if every wall on g is closed in solution and every dot
+// is open.
+func agrees(g *grid.Grid, dots []bool, solution []bool) bool {
+	for i, closed := range solution {
+		if !g.Open(i) && !closed || dots[i] && closed {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSolution marks the walls and dots that contradict the solution.
+func (w *window) checkSolution() {
+	w.withSolution(func(solution []bool) {
+		mistakes := 0
+		for i, obj := range w.objs {
+			switch {
+			case !w.g.Open(i) && !solution[i]:
+				obj.Set("state", "closedError")
+				mistakes++
+			case w.g.Dot(i) && solution[i]:
+				obj.Set("state", "dotError")
+				mistakes++
+			}
+		}
+		status := "Nurikabe - No mistakes so far"
+		if mistakes > 0 {
+			status = "Nurikabe - " + strconv.Itoa(mistakes) + " mistakes"
+		}
+		if !w.uniqueSolution {
+			status += " (one of several solutions)"
+		}
+		w.setStatus(status)
+	})
+}
+
+// showHint fixes one tile that differs from the solution, preferring walls
+// placed by mistake. Hints are counted in the result of the game.
+func (w *window) showHint() {
+	if w.qStatus().Bool("finished") {
+		return
+	}
+	w.withSolution(func(solution []bool) {
+		w.setStatus("Nurikabe - " + w.levelTitle())
+		hint := w.hintTile(solution)
+		if hint == -1 {
+			return
+		}
+		w.hints++
+		to := markDot
+		if solution[hint] {
+			to = markClosed
+		}
+		w.play(move{{hint, w.mark(hint), to}})
+	})
+}
+
+// hintTile returns a wall placed by mistake, else a missing wall, or -1 when
+// the board matches solution.
+func (w *window) hintTile(solution []bool) int {
+	for i, closed := range solution {
+		if !w.g.Open(i) && !closed {
+			return i
+		}
+	}
+	for i, closed := range solution {
+		if w.g.Open(i) && closed {
+			return i
+		}
+	}
+	return -1
+}
+
+// revealSolution ends the game without a record and fills in the solution
+// one tile at a time. Finished games, also those won while solving, are left
+// as they are.
+func (w *window) revealSolution() {
+	if w.qStatus().Bool("finished") {
+		return
+	}
+	w.withSolution(func(solution []bool) {
+		if w.qStatus().Bool("finished") {
+			return
+		}
+		w.setTimer(false)
+		w.setStatus("Nurikabe - Revealed")
+		w.clearActions()
+		if w.currentDifficulty == endlessDifficulty {
+			w.addAction("Next", actionNext)
+		} else {
+			w.records.Log(w.currentDifficulty, w.currentLevel, stats.Result{
+				Steps:    w.qStepsText().Int("moves"),
+				Seconds:  w.clock.seconds(),
+				Hints:    w.hints,
+				Revealed: true,
+			})
+			delete(w.profile.Saves, w.saveKey())
+			w.saveProfile()
+		}
+		for _, obj := range w.objs {
+			obj.Set("enabled", false)
+		}
+
+		generation := w.generation
+		go func() {
+			for i := range solution {
+				qml.Lock()
+				if generation != w.generation || w.currentMode != nurikabePage {
+					qml.Unlock()
+					return
+				}
+				changed := w.g.Open(i) == solution[i]
+				if changed {
+					w.g.Toggle(i)
+					state := "open"
+					if solution[i] {
+						state = "closed"
+					}
+					w.objs[i].Set("state", state)
+				}
+				qml.Unlock()
+				if changed {
+					time.Sleep(revealDelay)
+				}
+			}
+		}()
+	})
+}
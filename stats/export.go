@@ -0,0 +1,188 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportVersion is the version written by WriteJSON.
+//
+// The exported JSON document looks like
+//
+//	{
+//	  "version": 1,
+//	  "levels": [
+//	    {
+//	      "difficulty": "1-easy",
+//	      "level": 3,
+//	      "best": {"steps": {"steps": 9, "seconds": 31}, "time": {...}},
+//	      "attempts": [{"steps": 9, "seconds": 31, "time": "2014-08-01T10:00:00Z"}]
+//	    }
+//	  ]
+//	}
+//
+// "best" is keyed by policy name. Zero steps, seconds and hints are omitted.
+// Attempts where the solution was revealed have "revealed": true and never
+// count as best.
+// Daily puzzles use the "daily" difficulty with the date as level, 20140801.
+const ExportVersion = 1
+
+// csvHeader is the first row of a CSV export. Every following row is either
+// a "best" row for one policy or an "attempt" row with its finish time.
+var csvHeader = []string{"record", "difficulty", "level", "policy", "time", "steps", "seconds", "hints", "revealed"}
+
+// csvColumns is the number of columns in exports written before reveals were
+// recorded, which are still read.
+const csvColumns = 8
+
+type exportFile struct {
+	Version int            `json:"version"`
+	Levels  []*exportLevel `json:"levels"`
+}
+
+type exportLevel struct {
+	Difficulty string             `json:"difficulty"`
+	Level      int                `json:"level"`
+	Best       map[string]*Result `json:"best,omitempty"`
+	Attempts   []*Attempt         `json:"attempts,omitempty"`
+}
+
+// WriteJSON exports all records and attempts in the documented JSON format.
+func (r *Records) WriteJSON(w io.Writer) error {
+	f := &exportFile{Version: ExportVersion, Levels: make([]*exportLevel, 0, r.Length())}
+	for _, rec := range append(r.All(), r.Dailies()...) {
+		f.Levels = append(f.Levels, &exportLevel{
+			Difficulty: rec.Difficulty,
+			Level:      rec.Lvl,
+			Best:       rec.Bests,
+			Attempts:   rec.Attempts,
+		})
+	}
+	dat, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(dat, '\n'))
+	return err
+}
+
+// ReadJSON reads records written by WriteJSON.
+func ReadJSON(input io.Reader) (*Records, error) {
+	var f exportFile
+	if err := json.NewDecoder(input).Decode(&f); err != nil {
+		return nil, err
+	}
+	if f.Version != ExportVersion {
+		return nil, errors.New("unsupported export version " + strconv.Itoa(f.Version))
+	}
+	r := New(nil)
+	for i, l := range f.Levels {
+		if l == nil {
+			return nil, errors.New("level " + strconv.Itoa(i+1) + ": empty level")
+		}
+		rec := r.level(l.Difficulty, l.Level)
+		for name, res := range l.Best {
+			if res == nil {
+				return nil, errors.New("level " + strconv.Itoa(i+1) + ": empty best " + name)
+			}
+			rec.Bests[name] = res
+		}
+		for _, a := range l.Attempts {
+			if a == nil {
+				return nil, errors.New("level " + strconv.Itoa(i+1) + ": empty attempt")
+			}
+		}
+		rec.Attempts = append(rec.Attempts, l.Attempts...)
+	}
+	return r, nil
+}
+
+// WriteCSV exports all records and attempts, one row each.
+func (r *Records) WriteCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	if err := c.Write(csvHeader); err != nil {
+		return err
+	}
+	row := func(kind string, rec *LevelRecord, policy, t string, res *Result) error {
+		return c.Write([]string{kind, rec.Difficulty, strconv.Itoa(rec.Lvl), policy, t,
+			strconv.Itoa(res.Steps), strconv.Itoa(res.Seconds), strconv.Itoa(res.Hints), strconv.FormatBool(res.Revealed)})
+	}
+	for _, rec := range append(r.All(), r.Dailies()...) {
+		for _, p := range Policies {
+			if res, ok := rec.Best(p); ok {
+				if err := row("best", rec, p.Name(), "", res); err != nil {
+					return err
+				}
+			}
+		}
+		for _, a := range rec.Attempts {
+			if err := row("attempt", rec, "", a.Time.Format(time.RFC3339), &a.Result); err != nil {
+				return err
+			}
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// ReadCSV reads records written by WriteCSV.
+func ReadCSV(input io.Reader) (*Records, error) {
+	c := csv.NewReader(input)
+	header, err := c.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) != csvColumns && len(header) != len(csvHeader) {
+		return nil, errors.New("invalid csv header")
+	}
+	for i, h := range header {
+		if csvHeader[i] != h {
+			return nil, errors.New("invalid csv header " + h)
+		}
+	}
+
+	r := New(nil)
+	for line := 2; ; line++ {
+		row, err := c.Read()
+		if err == io.EOF {
+			return r, nil
+		} else if err != nil {
+			return nil, err
+		}
+		nums := make([]int, 4)
+		for i, col := range []int{2, 5, 6, 7} {
+			if nums[i], err = strconv.Atoi(row[col]); err != nil {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": invalid " + csvHeader[col])
+			}
+		}
+		rec := r.level(row[1], nums[0])
+		res := Result{Steps: nums[1], Seconds: nums[2], Hints: nums[3]}
+		if len(row) > csvColumns {
+			if res.Revealed, err = strconv.ParseBool(row[csvColumns]); err != nil {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": invalid revealed")
+			}
+		}
+		switch row[0] {
+		case "best":
+			rec.Bests[row[3]] = &res
+		case "attempt":
+			t, err := time.Parse(time.RFC3339, row[4])
+			if err != nil {
+				return nil, errors.New("line " + strconv.Itoa(line) + ": invalid time")
+			}
+			rec.Attempts = append(rec.Attempts, &Attempt{Result: res, Time: t})
+		default:
+			return nil, errors.New("line " + strconv.Itoa(line) + ": unknown record " + row[0])
+		}
+	}
+}
+
+// Import merges other into r, see Merge. Returns the number of levels that
+// changed.
+func (r *Records) Import(other *Records) int {
+	return len(Merge(r, other))
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Change describes how Merge updated a single level.
+type Change struct {
+	Difficulty string
+	Lvl        int
+	Policies   []string // policies with a new best result
+	Attempts   int      // attempts added
+}
+
+func (c *Change) String() string {
+	s := c.Difficulty + " " + strconv.Itoa(c.Lvl) + ":"
+	if len(c.Policies) > 0 {
+		s += " new best (" + strings.Join(c.Policies, ", ") + ")"
+	}
+	if c.Attempts > 0 {
+		s += " " + strconv.Itoa(c.Attempts) + " attempts added"
+	}
+	return s
+}
+
+// Merge merges the records of b into a by level, daily puzzles included. For every policy the better
+// of the two results is kept and attempts missing from a are added. Returns
+// the levels of a that changed, in level order.
+func Merge(a, b *Records) []*Change {
+	changes := make([]*Change, 0)
+	for _, o := range append(b.All(), b.Dailies()...) {
+		rec := a.level(o.Difficulty, o.Lvl)
+		c := &Change{Difficulty: o.Difficulty, Lvl: o.Lvl}
+		for _, p := range Policies {
+			res, ok := o.Best(p)
+			if !ok {
+				continue
+			}
+			if best, _ := rec.Best(p); p.Better(res, best) {
+				cpy := *res
+				rec.Bests[p.Name()] = &cpy
+				c.Policies = append(c.Policies, p.Name())
+			}
+		}
+
+		seen := make(map[string]bool, len(rec.Attempts))
+		for _, a := range rec.Attempts {
+			seen[a.key()] = true
+		}
+		for _, a := range o.Attempts {
+			if !seen[a.key()] {
+				cpy := *a
+				rec.Attempts = append(rec.Attempts, &cpy)
+				seen[a.key()] = true
+				c.Attempts++
+			}
+		}
+		sort.Sort(attemptList(rec.Attempts))
+
+		if len(c.Policies) > 0 || c.Attempts > 0 {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// key identifies an attempt across exports, which only keep whole seconds.
+func (a *Attempt) key() string {
+	return strconv.FormatInt(a.Time.Unix(), 10) + "/" + strconv.Itoa(a.Steps) + "/" +
+		strconv.Itoa(a.Seconds) + "/" + strconv.Itoa(a.Hints) + "/" + strconv.FormatBool(a.Revealed)
+}
+
+type attemptList []*Attempt
+
+// Len is part of sort.Interface.
+func (a attemptList) Len() int {
+	return len(a)
+}
+
+// Swap is part of sort.Interface.
+func (a attemptList) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+// Less is part of sort.Interface.
+func (a attemptList) Less(i, j int) bool {
+	return a[i].Time.Before(a[j].Time)
+}
@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func attempt(steps, seconds, hints int, t int64) *Attempt {
+	return &Attempt{Result: Result{Steps: steps, Seconds: seconds, Hints: hints}, Time: time.Unix(t, 0)}
+}
+
+func buildRecords(attempts ...*Attempt) *Records {
+	r := New(nil)
+	for _, a := range attempts {
+		r.Log("1-easy", 1, a.Result)
+		rec := r.level("1-easy", 1)
+		rec.Attempts[len(rec.Attempts)-1].Time = a.Time
+	}
+	return r
+}
+
+func TestMerge(t *testing.T) {
+	a := buildRecords(attempt(10, 50, 0, 100), attempt(12, 20, 0, 200))
+	b := buildRecords(attempt(8, 90, 1, 300), attempt(12, 20, 0, 200))
+	b.Log("2-medium", 4, Result{Steps: 3, Seconds: 3})
+
+	changes := Merge(a, b)
+	if len(changes) != 2 {
+		t.Fatal("Invalid change count", changes)
+	}
+	if c := changes[0]; c.Attempts != 1 || len(c.Policies) != 1 || c.Policies[0] != "steps" {
+		t.Fatal("Invalid change", c)
+	}
+
+	rec, _ := a.Level("1-easy", 1)
+	if len(rec.Attempts) != 3 || rec.Attempts[2].Steps != 8 {
+		t.Fatal("Invalid attempts", rec.Attempts)
+	}
+	expected := map[Policy]int{StepsFirst: 8, TimeFirst: 12, Score: 12, NoHints: 10}
+	for p, steps := range expected {
+		if best, _ := rec.Best(p); best.Steps != steps {
+			t.Fatal("Invalid best", p.Name(), best)
+		}
+	}
+
+	if changes := Merge(a, b); len(changes) != 0 {
+		t.Fatal("Merge not idempotent", changes)
+	}
+}
+
+func TestExport(t *testing.T) {
+	r := buildRecords(attempt(10, 50, 0, 100), attempt(8, 90, 1, 300))
+	r.Log("1-easy", 1, Result{Steps: 2, Seconds: 5, Revealed: true})
+	var j, c bytes.Buffer
+	if err := r.WriteJSON(&j); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteCSV(&c); err != nil {
+		t.Fatal(err)
+	}
+
+	fromJson, err := ReadJSON(&j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromCsv, err := ReadCSV(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, other := range []*Records{fromJson, fromCsv} {
+		if changes := Merge(r, other); len(changes) != 0 {
+			t.Fatal("Export lost data", changes)
+		}
+		if changes := Merge(New(nil), other); len(changes) != 1 || changes[0].Attempts != 3 {
+			t.Fatal("Invalid import", changes)
+		}
+	}
+}
+
+var invalidExports = []string{
+	`{"version":2,"levels":[]}`,
+	`{"version":1,"levels":[null]}`,
+	`{"version":1,"levels":[{"difficulty":"1-easy","level":1,"best":{"steps":null}}]}`,
+	`{"version":1,"levels":[{"difficulty":"1-easy","level":1,"attempts":[null]}]}`,
+	`{"version":1,"levels":[`,
+}
+
+func TestReadJSONInvalid(t *testing.T) {
+	for _, s := range invalidExports {
+		if _, err := ReadJSON(strings.NewReader(s)); err == nil {
+			t.Fatal("Invalid export read", s)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package stats
+
+// Result is a single completed play of a level.
+type Result struct {
+	Steps   int `json:"steps,omitempty"`
+	Seconds int `json:"seconds,omitempty"`
+	Hints   int `json:"hints,omitempty"`
+	// Revealed is set when the solution was shown instead of solved. Such
+	// results are kept as attempts but never become records.
+	Revealed bool `json:"revealed,omitempty"`
+}
+
+// Policy decides how results for the same level are ranked.
+type Policy interface {
+	Name() string
+	// Better returns true if a should replace b as the best result.
+	// b is nil when nothing has been recorded yet.
+	Better(a, b *Result) bool
+}
+
+var (
+	StepsFirst Policy = stepsFirst{}
+	TimeFirst  Policy = timeFirst{}
+	Score      Policy = &Weighted{StepWeight: 1, SecondWeight: 1}
+	NoHints    Policy = noHints{}
+)
+
+// Policies lists every ranking policy a record is kept for, in display order.
+var Policies = []Policy{StepsFirst, TimeFirst, Score, NoHints}
+
+func PolicyByName(name string) (Policy, bool) {
+	for _, p := range Policies {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// fewer steps wins, then fewer seconds
+type stepsFirst struct{}
+
+func (stepsFirst) Name() string {
+	return "steps"
+}
+
+func (stepsFirst) Better(a, b *Result) bool {
+	if b == nil {
+		return true
+	}
+	if a.Steps == b.Steps {
+		return a.Seconds < b.Seconds
+	}
+	return a.Steps < b.Steps
+}
+
+// fewer seconds wins, then fewer steps
+type timeFirst struct{}
+
+func (timeFirst) Name() string {
+	return "time"
+}
+
+func (timeFirst) Better(a, b *Result) bool {
+	if b == nil {
+		return true
+	}
+	if a.Seconds == b.Seconds {
+		return a.Steps < b.Steps
+	}
+	return a.Seconds < b.Seconds
+}
+
+// Weighted ranks results by a weighted sum of steps and seconds, lowest wins.
+type Weighted struct {
+	StepWeight   int
+	SecondWeight int
+}
+
+func (w *Weighted) Name() string {
+	return "score"
+}
+
+func (w *Weighted) Score(r *Result) int {
+	return r.Steps*w.StepWeight + r.Seconds*w.SecondWeight
+}
+
+func (w *Weighted) Better(a, b *Result) bool {
+	if b == nil {
+		return true
+	}
+	return w.Score(a) < w.Score(b)
+}
+
+// only results without hints count, ranked steps first
+type noHints struct{}
+
+func (noHints) Name() string {
+	return "no hints"
+}
+
+func (noHints) Better(a, b *Result) bool {
+	return a.Hints == 0 && StepsFirst.Better(a, b)
+}
@@ -8,26 +8,68 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"time"
+
+	"github.com/ostlerc/nurikabe/pack"
 )
 
+// DailyDifficulty is the difficulty of daily puzzle records. Their level is
+// the date as yyyymmdd, see DateLevel.
+const DailyDifficulty = "daily"
+
 type Records struct {
 	Stats  map[string]*LevelRecord `json:"stats"`
+	Daily  map[string]*LevelRecord `json:"daily,omitempty"`
 	sorter map[string]int
+	policy Policy
+}
+
+// Policy returns the policy used by String and Log to report records.
+func (r *Records) Policy() Policy {
+	if r.policy == nil {
+		return StepsFirst
+	}
+	return r.policy
+}
+
+func (r *Records) SetPolicy(p Policy) {
+	r.policy = p
 }
 
 func (r *Records) Level(difficulty string, lvl int) (*LevelRecord, bool) {
-	v, ok := r.Stats[strconv.Itoa(lvl)+difficulty]
+	v, ok := r.records(difficulty)[strconv.Itoa(lvl)+difficulty]
 	return v, ok
 }
 
+// records returns the map holding records of difficulty.
+func (r *Records) records(difficulty string) map[string]*LevelRecord {
+	if difficulty == DailyDifficulty {
+		if r.Daily == nil {
+			r.Daily = make(map[string]*LevelRecord)
+		}
+		return r.Daily
+	}
+	return r.Stats
+}
+
 func (r *Records) Length() int {
 	return len(r.Stats)
 }
 
+// All returns the level pack records sorted by difficulty and level.
 func (r *Records) All() []*LevelRecord {
-	recs := make([]*LevelRecord, len(r.Stats), len(r.Stats))
+	return sorted(r.Stats)
+}
+
+// Dailies returns the daily puzzle records sorted by date.
+func (r *Records) Dailies() []*LevelRecord {
+	return sorted(r.Daily)
+}
+
+func sorted(m map[string]*LevelRecord) []*LevelRecord {
+	recs := make([]*LevelRecord, len(m), len(m))
 	i := 0
-	for _, rec := range r.Stats {
+	for _, rec := range m {
 		recs[i] = rec
 		i++
 	}
@@ -55,14 +97,53 @@ func (r *recordList) Less(i, j int) bool {
 	if r.recs[i].Difficulty == r.recs[j].Difficulty {
 		return r.recs[i].Lvl < r.recs[j].Lvl
 	}
-	return r.recs[i].Difficulty < r.recs[j].Difficulty
+	return pack.Less(r.recs[i].Difficulty, r.recs[j].Difficulty)
 }
 
 type LevelRecord struct {
 	Difficulty string
 	Lvl        int
-	Steps      int `json:"steps,omitempty"`
-	Seconds    int `json:"seconds,omitempty"`
+	Bests      map[string]*Result `json:"best,omitempty"`
+	Attempts   []*Attempt         `json:"attempts,omitempty"`
+
+	// Steps and Seconds hold steps first records saved before policies
+	// existed. They are moved into Bests on Load.
+	Steps   int `json:"steps,omitempty"`
+	Seconds int `json:"seconds,omitempty"`
+}
+
+// Attempt is a completed play of a level and when it finished.
+type Attempt struct {
+	Result
+	Time time.Time `json:"time"`
+}
+
+// Solved returns true if the level was completed without revealing it.
+func (l *LevelRecord) Solved() bool {
+	return len(l.Bests) > 0
+}
+
+// Best returns the best result for this level under policy p.
+func (l *LevelRecord) Best(p Policy) (*Result, bool) {
+	res, ok := l.Bests[p.Name()]
+	return res, ok
+}
+
+func (l *LevelRecord) migrate() {
+	if l.Bests == nil {
+		l.Bests = make(map[string]*Result, len(Policies))
+	}
+	if l.Steps == 0 && l.Seconds == 0 {
+		return
+	}
+	old := &Result{Steps: l.Steps, Seconds: l.Seconds}
+	for _, p := range Policies {
+		if best, _ := l.Best(p); p.Better(old, best) {
+			res := *old
+			l.Bests[p.Name()] = &res
+		}
+	}
+	l.Steps, l.Seconds = 0, 0
 }
 
 func New(sortMap map[string]int) *Records {
@@ -84,15 +165,27 @@ func Load(file string, sorter map[string]int) (*Records, error) {
 	if err != nil {
 		return nil, err
 	}
+	if recs.Stats == nil {
+		recs.Stats = make(map[string]*LevelRecord, 30)
+	}
+	for _, rec := range recs.Stats {
+		rec.migrate()
+	}
+	for _, rec := range recs.Daily {
+		rec.migrate()
+	}
 	return recs, nil
 }
 
 func (r *Records) String(difficulty string, lvl int) string {
-	if rec, ok := r.Stats[strconv.Itoa(lvl)+difficulty]; !ok {
+	rec, ok := r.Level(difficulty, lvl)
+	if !ok {
 		return ""
-	} else {
-		return "record: " + strconv.Itoa(rec.Steps) + " steps, " + strconv.Itoa(rec.Seconds) + " seconds  "
 	}
+	if res, ok := rec.Best(r.Policy()); ok {
+		return "record: " + strconv.Itoa(res.Steps) + " steps, " + strconv.Itoa(res.Seconds) + " seconds  "
+	}
+	return ""
 }
 
 func (r *Records) Save(file string) error {
@@ -103,26 +196,64 @@ func (r *Records) Save(file string) error {
 	return ioutil.WriteFile(file, dat, 0600)
 }
 
-//Returns true if new stats were better than previous
-func (r *Records) Log(difficulty string, lvl, steps, seconds int) bool {
-	key := strconv.Itoa(lvl) + difficulty
-	rec, ok := r.Stats[key]
-	defer func() {
-		r.Stats[key] = rec
-	}()
+// Log records res for every policy, unless it was revealed. Returns true if
+// res was better than the previous record under the current policy.
+func (r *Records) Log(difficulty string, lvl int, res Result) bool {
+	rec := r.level(difficulty, lvl)
+	rec.Attempts = append(rec.Attempts, &Attempt{Result: res, Time: time.Now()})
+	if res.Revealed {
+		return false
+	}
+	better := false
+	for _, p := range Policies {
+		if best, _ := rec.Best(p); p.Better(&res, best) {
+			cpy := res
+			rec.Bests[p.Name()] = &cpy
+			if p == r.Policy() {
+				better = true
+			}
+		}
+	}
+	return better
+}
+
+// level returns the record for a level, creating it if needed.
+func (r *Records) level(difficulty string, lvl int) *LevelRecord {
+	rec, ok := r.Level(difficulty, lvl)
 	if !ok {
-		rec = &LevelRecord{Lvl: lvl, Difficulty: difficulty, Steps: steps, Seconds: seconds}
-		return true
-	}
-	if steps < rec.Steps {
-		rec.Steps = steps
-		rec.Seconds = seconds
-		return true
-	} else if steps == rec.Steps {
-		if seconds < rec.Seconds {
-			rec.Seconds = seconds
-			return true
+		rec = &LevelRecord{Lvl: lvl, Difficulty: difficulty}
+		rec.migrate()
+		r.records(difficulty)[strconv.Itoa(lvl)+difficulty] = rec
+	}
+	return rec
+}
+
+// DateLevel returns the daily puzzle level for the calendar day of t.
+func DateLevel(t time.Time) int {
+	y, m, d := t.Date()
+	return y*10000 + int(m)*100 + d
+}
+
+// LogDaily records res for the daily puzzle of day, see Log.
+func (r *Records) LogDaily(day time.Time, res Result) bool {
+	return r.Log(DailyDifficulty, DateLevel(day), res)
+}
+
+// Streak returns the number of consecutive days with a completed daily puzzle
+// ending at day. An unsolved day does not break the streak until it is over.
+func (r *Records) Streak(day time.Time) int {
+	solved := func(day time.Time) bool {
+		rec, ok := r.Level(DailyDifficulty, DateLevel(day))
+		return ok && rec.Solved()
+	}
+	if !solved(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for ; ; day = day.AddDate(0, 0, -1) {
+		if !solved(day) {
+			return streak
 		}
+		streak++
 	}
-	return false
 }
@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreak(t *testing.T) {
+	r := New(nil)
+	day := time.Date(2014, 8, 1, 0, 0, 0, 0, time.UTC)
+	for _, d := range []int{-5, -3, -2, -1} {
+		r.LogDaily(day.AddDate(0, 0, d), Result{Steps: 10})
+	}
+	if s := r.Streak(day); s != 3 {
+		t.Fatal("Invalid unsolved day streak", s)
+	}
+	r.LogDaily(day, Result{Steps: 10})
+	if s := r.Streak(day); s != 4 {
+		t.Fatal("Invalid streak", s)
+	}
+	if s := r.Streak(day.AddDate(0, 0, 2)); s != 0 {
+		t.Fatal("Invalid broken streak", s)
+	}
+	if r.Length() != 0 || len(r.Dailies()) != 5 {
+		t.Fatal("Daily records mixed with levels")
+	}
+}
+
+func TestRevealed(t *testing.T) {
+	r := New(nil)
+	day := time.Date(2014, 8, 1, 0, 0, 0, 0, time.UTC)
+	if r.Log("1-easy", 1, Result{Steps: 3, Revealed: true}) {
+		t.Fatal("Revealed result is a record")
+	}
+	rec, ok := r.Level("1-easy", 1)
+	if !ok || rec.Solved() || len(rec.Attempts) != 1 {
+		t.Fatal("Invalid revealed record", rec)
+	}
+	r.LogDaily(day.AddDate(0, 0, -1), Result{Steps: 10})
+	r.LogDaily(day, Result{Steps: 10, Revealed: true})
+	if s := r.Streak(day); s != 1 {
+		t.Fatal("Invalid revealed streak", s)
+	}
+	if s := r.Streak(day.AddDate(0, 0, 1)); s != 0 {
+		t.Fatal("Revealed day kept streak", s)
+	}
+}
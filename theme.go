@@ -0,0 +1,13 @@
+package main
+
+import "github.com/ostlerc/nurikabe/draw"
+
+// themeByName returns the theme called name, or the first theme for names
+// that are no longer known. The qml files read the current theme from the
+// "theme" context property.
+func themeByName(name string) *draw.Theme {
+	if t, ok := draw.ThemeByName(name); ok {
+		return t
+	}
+	return draw.Themes[0]
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"image"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ostlerc/nurikabe/draw"
+)
+
+const (
+	thumbnailDir      = ".thumbnails/"
+	thumbnailProvider = "thumbnail"
+	thumbnailCell     = 10
+)
+
+// thumbnailSource returns the image source of the level file thumbnail for the
+// current theme. Qt keeps loaded images by source, so the theme is part of it.
+func (w *window) thumbnailSource(file string) string {
+	theme := 0
+	for i, t := range draw.Themes {
+		if t == w.theme {
+			theme = i
+		}
+	}
+	return "image://" + thumbnailProvider + "/" + strconv.Itoa(theme) + "/" + url.PathEscape(file)
+}
+
+// thumbnail is the image provider for level thumbnails, called by qml off the
+// UI thread with the id of a thumbnailSource.
+func thumbnail(id string, width, height int) image.Image {
+	parts := strings.SplitN(id, "/", 2)
+	theme, err := strconv.Atoi(parts[0])
+	if err != nil || theme < 0 || theme >= len(draw.Themes) || len(parts) != 2 {
+		return image.NewRGBA(image.Rect(0, 0, 1, 1))
+	}
+	file, err := url.PathUnescape(parts[1])
+	if err != nil {
+		file = parts[1]
+	}
+	c := draw.Cache{
+		Dir:     thumbnailDir,
+		Options: draw.Options{Cell: thumbnailCell, Theme: draw.Themes[theme]},
+	}
+	img, err := c.Image(file)
+	if err != nil {
+		log.Println("thumbnail", file, err)
+	}
+	if img == nil {
+		return image.NewRGBA(image.Rect(0, 0, 1, 1))
+	}
+	return img
+}
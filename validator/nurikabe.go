@@ -246,16 +246,11 @@ func (n *nurikabeSolver) gardenPermutations(g *gardenSolver) {
 	}
 
 	steps := make([]int, 0, 4)
-	appendIf := func(pred bool, v int) {
-		if pred && !beenAt(g, v) { // not bottom of grid
-			steps = append(steps, v)
+	size(n).Neighbors(g.i, func(x int) {
+		if !beenAt(g, x-g.i) {
+			steps = append(steps, x-g.i)
 		}
-	}
-
-	appendIf(g.i/n.Columns() != n.Rows()-1, n.Columns()) // not bottom of grid
-	appendIf(g.i >= n.Columns(), -n.Columns())           // not top of grid
-	appendIf(g.i%n.Columns() != n.Columns()-1, 1)        // not right side of grid
-	appendIf(g.i%n.Columns() != 0, -1)                   // not left side of grid
+	})
 
 	for _, perm := range perms(steps, make(map[string]bool, 17)) {
 		orig := g.c
@@ -318,13 +313,9 @@ func (n *nurikabe) CheckWin(d GridData) bool {
 
 // This function detects quad blocks
 func (n *nurikabe) hasBlock() bool {
+	sz := size(n.d)
 	for i := 0; i < n.l; i++ {
-		if i/n.d.Columns() == n.d.Rows()-1 || // bottom of grid
-			i%n.d.Columns() == n.d.Columns()-1 || // right side of grid
-			n.d.Open(i) ||
-			n.d.Open(i+1) ||
-			n.d.Open(i+n.d.Columns()) ||
-			n.d.Open(i+n.d.Columns()+1) {
+		if b, ok := sz.Block(i); !ok || n.d.Open(b[0]) || n.d.Open(b[1]) || n.d.Open(b[2]) || n.d.Open(b[3]) {
 			continue
 		}
 		if Verbose {
@@ -352,10 +343,14 @@ func (n *nurikabe) openCountCorrect() bool {
 
 // This function counts 4-connected open squares at each garden count spot
 func (n *nurikabe) gardensAreCorrect() bool {
+	sz := size(n.d)
 	for i := 0; i < n.l; i++ {
 		if c := n.d.Count(i); c > 0 {
-			openTiles := make(map[int]bool)
-			if x := n.mark(i, openTiles, true); x != c {
+			x := 0
+			if n.d.Open(i) {
+				x = len(sz.Region(i, n.d.Open, nil))
+			}
+			if x != c {
 				if Verbose {
 					fmt.Println("gardens", x, "!=", c)
 				}
@@ -386,42 +381,9 @@ func (n *nurikabe) singleWall() bool {
 		return false
 	}
 
-	found := make(map[int]bool)
-
-	c := n.mark(firstWall, found, false)
+	c := len(size(n.d).Region(firstWall, func(x int) bool { return !n.d.Open(x) }, nil))
 	if c != wallCount && Verbose {
 		fmt.Println("wall", c, "!=", wallCount)
 	}
 	return c == wallCount
 }
-
-func (n *nurikabe) mark(i int, found map[int]bool, open bool) int {
-	if i < 0 || i >= n.l {
-		return 0
-	}
-
-	if _, ok := found[i]; ok || n.d.Open(i) != open {
-		return 0
-	}
-
-	found[i] = true
-	ret := 1
-
-	if i/n.d.Columns() != n.d.Rows()-1 { // not bottom of grid
-		ret += n.mark(i+n.d.Columns(), found, open)
-	}
-
-	if i >= n.d.Columns() { // not top of grid
-		ret += n.mark(i-n.d.Columns(), found, open)
-	}
-
-	if i%n.d.Columns() != n.d.Columns()-1 { // not right side of grid
-		ret += n.mark(i+1, found, open)
-	}
-
-	if i%n.d.Columns() != 0 { // not left side of grid
-		ret += n.mark(i-1, found, open)
-	}
-
-	return ret
-}
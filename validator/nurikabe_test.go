@@ -41,6 +41,7 @@ var tests = []*vTest{
 type fakeGridData struct {
 	counts map[int]int
 	closed map[int]bool
+	dots   map[int]bool
 	rows   int
 	cols   int
 }
@@ -54,6 +55,10 @@ func (f *fakeGridData) Count(i int) int {
 	return f.counts[i]
 }
 
+func (f *fakeGridData) Dot(i int) bool {
+	return f.dots[i]
+}
+
 func (f *fakeGridData) Rows() int {
 	return f.rows
 }
@@ -0,0 +1,372 @@
+package validator
+
+import "github.com/ostlerc/nurikabe/geom"
+
+const (
+	unknownCell = iota
+	openCell
+	wallCell
+)
+
+// cellSolver finds solutions by deciding one tile at a time. Tiles that lead
+// to a contradiction either way are decided up front, and every remaining
+// choice is tried, so unlike Solve it finds all solutions.
+type cellSolver struct {
+	d     GridData
+	v     GridValidator
+	size  geom.Size
+	l     int
+	total int // open tiles in a solution
+	limit int
+
+	cells []byte
+	comp  []int  // open component of each open tile
+	seen  []bool // tiles already in a region
+	reach []bool // open tiles an unfinished island can reach
+	dist  []int  // distance from the island being grown
+
+	solutions [][]bool
+	guesses   int // tiles branched on
+	graded    int // guesses made before the first solution
+
+	stop    func() bool
+	stopped bool
+}
+
+// Difficulty is how hard a puzzle is to solve, see Grade.
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Medium
+	Hard
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	}
+	return "hard"
+}
+
+// Solutions returns up to limit distinct solutions of d as closed tiles. It can
+// take a long time on large puzzles with big islands and many solutions.
+func Solutions(d GridData, v GridValidator, limit int) [][]bool {
+	solutions, _, _ := Check(d, v, limit, nil)
+	return solutions
+}
+
+// SolutionsFrom returns up to limit solutions of d that keep its closed tiles
+// closed and the tiles set in open open, like the walls and dots of a game in
+// progress. It returns none if those tiles can not be part of a solution.
+func SolutionsFrom(d GridData, v GridValidator, limit int, open []bool) [][]bool {
+	s := newCellSolver(d, v, limit)
+	for i := range s.cells {
+		switch {
+		case s.cells[i] != unknownCell:
+		case !d.Open(i):
+			s.cells[i] = wallCell
+		case open != nil && open[i]:
+			s.cells[i] = openCell
+		}
+	}
+	s.search()
+	return s.solutions
+}
+
+// Unique returns true if d has exactly one solution.
+func Unique(d GridData, v GridValidator) bool {
+	return len(Solutions(d, v, 2)) == 1
+}
+
+// Grade rates d by the number of guesses needed to find a solution once
+// every forced tile is filled in. Returns false if d has no solution.
+func Grade(d GridData, v GridValidator) (Difficulty, bool) {
+	solutions, grade, _ := Check(d, v, 1, nil)
+	return grade, len(solutions) > 0
+}
+
+// Check finds up to limit solutions of d like Solutions, and grades d like
+// Grade in the same search. The search gives up as soon as stop returns true,
+// returning false, so callers can drop a check they no longer need. A nil stop
+// never gives up.
+func Check(d GridData, v GridValidator, limit int, stop func() bool) ([][]bool, Difficulty, bool) {
+	s := newCellSolver(d, v, limit)
+	s.stop = stop
+	s.search()
+	if s.stopped {
+		return nil, Hard, false
+	}
+	switch {
+	case len(s.solutions) == 0:
+		return nil, Hard, true
+	case s.graded <= 5:
+		return s.solutions, Easy, true
+	case s.graded <= 20:
+		return s.solutions, Medium, true
+	}
+	return s.solutions, Hard, true
+}
+
+func newCellSolver(d GridData, v GridValidator, limit int) *cellSolver {
+	s := &cellSolver{
+		d:     d,
+		v:     v,
+		size:  size(d),
+		l:     d.Rows() * d.Columns(),
+		limit: limit,
+	}
+	s.cells = make([]byte, s.l)
+	s.comp = make([]int, s.l)
+	s.seen = make([]bool, s.l)
+	s.reach = make([]bool, s.l)
+	s.dist = make([]int, s.l)
+	for i := 0; i < s.l; i++ {
+		if c := d.Count(i); c > 0 {
+			s.cells[i] = openCell
+			s.total += c
+		}
+	}
+	return s
+}
+
+func (s *cellSolver) Open(i int) bool {
+	return s.cells[i] != wallCell
+}
+
+func (s *cellSolver) Count(i int) int {
+	return s.d.Count(i)
+}
+
+func (s *cellSolver) Rows() int {
+	return s.d.Rows()
+}
+
+func (s *cellSolver) Columns() int {
+	return s.d.Columns()
+}
+
+// search returns true once limit solutions are found or stop returns true.
+// Otherwise cells are left as they were.
+func (s *cellSolver) search() bool {
+	if s.stop != nil && s.stop() {
+		s.stopped = true
+		return true
+	}
+	saved := make([]byte, s.l)
+	copy(saved, s.cells)
+	if s.propagate() {
+		i := s.next()
+		if i == -1 {
+			if s.v.CheckWin(s) {
+				closed := make([]bool, s.l)
+				for i, c := range s.cells {
+					closed[i] = c == wallCell
+				}
+				if len(s.solutions) == 0 {
+					s.graded = s.guesses
+				}
+				s.solutions = append(s.solutions, closed)
+			}
+			if len(s.solutions) >= s.limit {
+				return true
+			}
+		} else {
+			s.guesses++
+			for _, c := range []byte{openCell, wallCell} {
+				s.cells[i] = c
+				if s.search() {
+					return true
+				}
+			}
+		}
+	}
+	copy(s.cells, saved)
+	return false
+}
+
+// next returns the unknown tile to branch on, preferring tiles next to open
+// tiles, or -1 when every tile is decided.
+func (s *cellSolver) next() int {
+	ret := -1
+	for i, c := range s.cells {
+		if c != unknownCell {
+			continue
+		}
+		near := false
+		s.size.Neighbors(i, func(x int) { near = near || s.cells[x] == openCell })
+		if near {
+			return i
+		}
+		if ret == -1 {
+			ret = i
+		}
+	}
+	return ret
+}
+
+// propagate decides every unknown tile where one choice is a contradiction.
+// Returns false if the cells can not be solved.
+func (s *cellSolver) propagate() bool {
+	if !s.valid() {
+		return false
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, c := range s.cells {
+			if c != unknownCell {
+				continue
+			}
+			s.cells[i] = openCell
+			open := s.valid()
+			s.cells[i] = wallCell
+			wall := s.valid()
+			switch {
+			case !open && !wall:
+				return false
+			case !open:
+				changed = true
+			case !wall:
+				s.cells[i] = openCell
+				changed = true
+			default:
+				s.cells[i] = unknownCell
+			}
+		}
+	}
+	return true
+}
+
+// valid returns false if the decided tiles already break a rule.
+func (s *cellSolver) valid() bool {
+	open, walls := 0, 0
+	for _, c := range s.cells {
+		if c == openCell {
+			open++
+		} else if c == wallCell {
+			walls++
+		}
+	}
+	if open > s.total || walls > s.l-s.total {
+		return false
+	}
+
+	for i := 0; i < s.l; i++ {
+		if b, ok := s.size.Block(i); ok && s.cells[b[0]] == wallCell && s.cells[b[1]] == wallCell &&
+			s.cells[b[2]] == wallCell && s.cells[b[3]] == wallCell {
+			return false
+		}
+	}
+
+	// label open components with their size and clue
+	for i := range s.comp {
+		s.comp[i] = -1
+		s.seen[i] = false
+	}
+	isOpen := func(x int) bool { return s.cells[x] == openCell }
+	sizes, clues := make([]int, 0, 8), make([]int, 0, 8)
+	for i, c := range s.cells {
+		if c != openCell || s.seen[i] {
+			continue
+		}
+		id := len(sizes)
+		sizes, clues = append(sizes, 0), append(clues, 0)
+		for _, x := range s.size.Region(i, isOpen, s.seen) {
+			s.comp[x] = id
+			sizes[id]++
+			if c := s.d.Count(x); c > 0 {
+				if clues[id] != 0 {
+					clues[id] = -1 // two clues in one island
+				} else {
+					clues[id] = c
+				}
+			}
+		}
+		if clues[id] < 0 || clues[id] > 0 && sizes[id] > clues[id] {
+			return false
+		}
+	}
+
+	// tiles next to an island with a clue may only join that island
+	joins := func(x, id int) bool {
+		if s.cells[x] == wallCell {
+			return false
+		}
+		ok := true
+		s.size.Neighbors(x, func(y int) {
+			if s.cells[y] == openCell && s.comp[y] != id && clues[s.comp[y]] > 0 {
+				ok = false
+			}
+		})
+		return ok
+	}
+	// open tiles must be close enough to an unfinished island to join it
+	for i := range s.reach {
+		s.reach[i] = false
+	}
+	for id, size := range sizes {
+		if clues[id] <= 0 || size == clues[id] {
+			continue
+		}
+		for i := range s.dist {
+			s.dist[i] = -1
+		}
+		queue := make([]int, 0, clues[id])
+		for i, c := range s.comp {
+			if c == id {
+				s.dist[i] = 0
+				queue = append(queue, i)
+			}
+		}
+		room := size
+		for len(queue) > 0 {
+			x := queue[0]
+			queue = queue[1:]
+			s.reach[x] = true
+			if s.dist[x] == clues[id]-size {
+				continue
+			}
+			s.size.Neighbors(x, func(y int) {
+				if s.dist[y] == -1 && joins(y, id) {
+					s.dist[y] = s.dist[x] + 1
+					queue = append(queue, y)
+					room++
+				}
+			})
+		}
+		if room < clues[id] {
+			return false
+		}
+	}
+	for i, c := range s.cells {
+		if c == openCell && clues[s.comp[i]] == 0 && !s.reach[i] {
+			return false
+		}
+	}
+
+	// walls must be able to join up through unknown tiles
+	first := -1
+	for i, c := range s.cells {
+		if c == wallCell {
+			first = i
+			break
+		}
+	}
+	if first != -1 {
+		for i := range s.seen {
+			s.seen[i] = false
+		}
+		for _, x := range s.size.Region(first, func(x int) bool { return s.cells[x] != openCell }, s.seen) {
+			if s.cells[x] == wallCell {
+				walls--
+			}
+		}
+		if walls != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+type uTest struct {
+	count     map[int]int
+	cols      int
+	rows      int
+	solutions int
+	closed    []int
+}
+
+var uniqueTests = []*uTest{
+	&uTest{map[int]int{5: 2, 6: 2}, 3, 3, 2, nil},
+	&uTest{map[int]int{0: 2, 5: 3}, 3, 3, 1, []int{1, 4, 6, 7}},
+	&uTest{map[int]int{2: 6, 15: 2, 18: 5}, 5, 4, 1, []int{3, 8, 10, 11, 12, 13, 17}},
+	&uTest{map[int]int{0: 3, 1: 2}, 3, 3, 0, nil},
+}
+
+func TestSolutions(t *testing.T) {
+	for _, u := range uniqueTests {
+		d := &fakeGridData{counts: u.count, rows: u.rows, cols: u.cols}
+		s := Solutions(d, NewNurikabe(), 10)
+		if len(s) != u.solutions {
+			t.Fatal("Invalid solution count", len(s), u)
+		}
+		if Unique(d, NewNurikabe()) != (u.solutions == 1) {
+			t.Fatal("Invalid uniqueness", u)
+		}
+		if u.closed == nil {
+			continue
+		}
+		closed := 0
+		for _, c := range s[0] {
+			if c {
+				closed++
+			}
+		}
+		if closed != len(u.closed) {
+			t.Fatal("Invalid solution", s[0], u)
+		}
+		for _, i := range u.closed {
+			if !s[0][i] {
+				t.Fatal("Invalid solution", s[0], u)
+			}
+		}
+	}
+}
+
+func TestGrade(t *testing.T) {
+	for _, u := range uniqueTests {
+		d := &fakeGridData{counts: u.count, rows: u.rows, cols: u.cols}
+		if g, ok := Grade(d, NewNurikabe()); ok != (u.solutions > 0) {
+			t.Fatal("Invalid grade", g, ok, u)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, u := range uniqueTests {
+		d := &fakeGridData{counts: u.count, rows: u.rows, cols: u.cols}
+		solutions, grade, ok := Check(d, NewNurikabe(), 2, nil)
+		want := u.solutions
+		if want > 2 {
+			want = 2
+		}
+		if !ok || len(solutions) != want {
+			t.Fatal("Invalid check", len(solutions), ok, u)
+		}
+		if g, _ := Grade(d, NewNurikabe()); u.solutions > 0 && g != grade {
+			t.Fatal("Check grade differs", grade, g, u)
+		}
+
+		calls := 0
+		stop := func() bool {
+			calls++
+			return true
+		}
+		if solutions, _, ok := Check(d, NewNurikabe(), 2, stop); ok || solutions != nil || calls != 1 {
+			t.Fatal("Check not stopped", solutions, ok, calls, u)
+		}
+	}
+}
+
+func TestSolutionsFrom(t *testing.T) {
+	u := uniqueTests[0]
+	d := &fakeGridData{counts: u.count, rows: u.rows, cols: u.cols}
+	all := Solutions(d, NewNurikabe(), 10)
+	if len(all) != 2 {
+		t.Fatal("Invalid solution count", len(all))
+	}
+	for k, want := range all {
+		other := all[1-k]
+		for i := range want {
+			if want[i] == other[i] {
+				continue
+			}
+			// a wall or a dot where only want has one picks want
+			var s [][]bool
+			if want[i] {
+				d.closed = map[int]bool{i: true}
+				s = SolutionsFrom(d, NewNurikabe(), 10, nil)
+			} else {
+				d.closed = nil
+				open := make([]bool, len(want))
+				open[i] = true
+				s = SolutionsFrom(d, NewNurikabe(), 10, open)
+			}
+			if len(s) != 1 || !reflect.DeepEqual(s[0], want) {
+				t.Fatal("Invalid seeded solutions", i, s, want)
+			}
+		}
+	}
+
+	d.closed = nil
+	open := make([]bool, u.rows*u.cols)
+	for i := range open {
+		open[i] = true
+	}
+	if s := SolutionsFrom(d, NewNurikabe(), 10, open); len(s) != 0 {
+		t.Fatal("Solution with every tile open", s)
+	}
+}
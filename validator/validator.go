@@ -7,6 +7,13 @@ type GridData interface {
 	Columns() int
 }
 
+// DotData is GridData that also knows the open tiles the player marked as
+// part of an island. Violations uses it when d implements it.
+type DotData interface {
+	GridData
+	Dot(int) bool
+}
+
 type GridValidator interface {
 	CheckWin(GridData) bool
 }
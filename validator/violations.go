@@ -0,0 +1,142 @@
+package validator
+
+import "github.com/ostlerc/nurikabe/geom"
+
+// Violation is a set of broken rules at a tile, see Violations.
+type Violation int
+
+const (
+	WallBlock    Violation = 1 << iota // part of a 2x2 block of walls
+	LargeIsland                        // island bigger than its clue
+	JoinedIsland                       // island with more than one clue
+	SmallIsland                        // walled off island too small for its clues
+	SplitWall                          // wall not joined to the largest wall
+)
+
+// Violations returns the broken rules at every tile of d. Walls and islands
+// can still be split by adding walls, so large and joined islands and split
+// walls are only reported once d has at least as many walls as a solution.
+// Tiles that are sure to be open, clues and the dots of a DotData, can not be
+// split though, so islands of them are reported as large or joined at once.
+func Violations(d GridData) []Violation {
+	sz := size(d)
+	l := sz.Len()
+	ret := make([]Violation, l)
+
+	walls, clues := 0, 0
+	for i := 0; i < l; i++ {
+		if !d.Open(i) {
+			walls++
+		}
+		clues += d.Count(i)
+	}
+	full := walls >= l-clues
+
+	sz.Blocks(func(b [4]int) {
+		if !d.Open(b[0]) && !d.Open(b[1]) && !d.Open(b[2]) && !d.Open(b[3]) {
+			for _, x := range b {
+				ret[x] |= WallBlock
+			}
+		}
+	})
+
+	dots, dotted := d.(DotData)
+	sure := func(i int) bool {
+		return d.Open(i) && (d.Count(i) > 0 || dotted && dots.Dot(i))
+	}
+	sz.Regions(func(a, b int) bool { return sure(a) == sure(b) }, func(comp []int) {
+		if !sure(comp[0]) {
+			return
+		}
+		count, sum := islandClues(d, comp)
+		var v Violation
+		switch {
+		case count > 1:
+			v = JoinedIsland
+		case count == 1 && len(comp) > sum:
+			v = LargeIsland
+		}
+		for _, x := range comp {
+			ret[x] |= v
+		}
+	})
+
+	var walled [][]int
+	largest := -1
+	sz.Regions(alike(d), func(comp []int) {
+		if !d.Open(comp[0]) {
+			if largest == -1 || len(comp) > len(walled[largest]) {
+				largest = len(walled)
+			}
+			walled = append(walled, comp)
+			return
+		}
+
+		count, sum := islandClues(d, comp)
+		var v Violation
+		switch {
+		case len(comp) < sum:
+			v = SmallIsland
+		case full && count > 1:
+			v = JoinedIsland
+		case full && count == 1 && len(comp) > sum:
+			v = LargeIsland
+		}
+		for _, x := range comp {
+			ret[x] |= v
+		}
+	})
+
+	if full {
+		for i, comp := range walled {
+			if i == largest {
+				continue
+			}
+			for _, x := range comp {
+				ret[x] |= SplitWall
+			}
+		}
+	}
+	return ret
+}
+
+// CompleteIslands returns true for the open tiles of islands that have a
+// single clue and as many tiles as it asks for.
+func CompleteIslands(d GridData) []bool {
+	sz := size(d)
+	ret := make([]bool, sz.Len())
+	sz.Regions(alike(d), func(comp []int) {
+		if !d.Open(comp[0]) {
+			return
+		}
+		count, sum := islandClues(d, comp)
+		if count == 1 && len(comp) == sum {
+			for _, x := range comp {
+				ret[x] = true
+			}
+		}
+	})
+	return ret
+}
+
+// islandClues returns the number of clues in the tiles of comp and their sum.
+func islandClues(d GridData, comp []int) (int, int) {
+	count, sum := 0, 0
+	for _, x := range comp {
+		if c := d.Count(x); c > 0 {
+			count++
+			sum += c
+		}
+	}
+	return count, sum
+}
+
+// size returns the rows and columns of d.
+func size(d GridData) geom.Size {
+	return geom.Size{Rows: d.Rows(), Cols: d.Columns()}
+}
+
+// alike returns true for tiles of d that are both open or both closed.
+func alike(d GridData) func(a, b int) bool {
+	return func(a, b int) bool { return d.Open(a) == d.Open(b) }
+}
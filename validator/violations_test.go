@@ -0,0 +1,73 @@
+package validator
+
+import "testing"
+
+type violationTest struct {
+	closed []int
+	count  map[int]int
+	cols   int
+	rows   int
+	want   map[int]Violation
+}
+
+var violationTests = []*violationTest{
+	// nothing closed yet
+	&violationTest{[]int{}, map[int]int{0: 2, 8: 2}, 3, 3, map[int]Violation{}},
+	&violationTest{[]int{0, 1, 3, 4}, map[int]int{8: 2}, 3, 3,
+		map[int]Violation{0: WallBlock, 1: WallBlock, 3: WallBlock, 4: WallBlock}},
+	// 3 walled off in the corner
+	&violationTest{[]int{1, 3}, map[int]int{0: 3, 8: 2}, 3, 3, map[int]Violation{0: SmallIsland}},
+	// enough walls, but the islands or walls are wrong
+	&violationTest{[]int{2, 3, 4}, map[int]int{0: 1, 1: 1}, 5, 1, map[int]Violation{0: JoinedIsland, 1: JoinedIsland}},
+	&violationTest{[]int{0, 2, 4}, map[int]int{1: 1, 3: 1}, 5, 1, map[int]Violation{2: SplitWall, 4: SplitWall}},
+	&violationTest{[]int{2, 3}, map[int]int{0: 1, 5: 3}, 6, 1, map[int]Violation{
+		0: LargeIsland, 1: LargeIsland, 4: SmallIsland, 5: SmallIsland}},
+}
+
+func TestViolations(t *testing.T) {
+	for _, v := range violationTests {
+		closed := make(map[int]bool, len(v.closed))
+		for _, i := range v.closed {
+			closed[i] = true
+		}
+		d := &fakeGridData{counts: v.count, closed: closed, rows: v.rows, cols: v.cols}
+		for i, got := range Violations(d) {
+			if got != v.want[i] {
+				t.Fatal("Invalid violation", i, got, v)
+			}
+		}
+	}
+}
+
+type dotTest struct {
+	dots  []int
+	count map[int]int
+	cols  int
+	rows  int
+	want  map[int]Violation
+}
+
+// clues and dots can not be split by walls, so these are reported before
+// the walls are placed
+var dotTests = []*dotTest{
+	&dotTest{[]int{}, map[int]int{0: 1, 1: 1}, 5, 1, map[int]Violation{0: JoinedIsland, 1: JoinedIsland}},
+	&dotTest{[]int{1}, map[int]int{0: 2, 2: 1}, 5, 1,
+		map[int]Violation{0: JoinedIsland, 1: JoinedIsland, 2: JoinedIsland}},
+	&dotTest{[]int{1, 2}, map[int]int{0: 2}, 5, 1, map[int]Violation{0: LargeIsland, 1: LargeIsland, 2: LargeIsland}},
+	&dotTest{[]int{1}, map[int]int{0: 2, 3: 1}, 5, 1, map[int]Violation{}},
+}
+
+func TestDotViolations(t *testing.T) {
+	for _, v := range dotTests {
+		dots := make(map[int]bool, len(v.dots))
+		for _, i := range v.dots {
+			dots[i] = true
+		}
+		d := &fakeGridData{counts: v.count, dots: dots, rows: v.rows, cols: v.cols}
+		for i, got := range Violations(d) {
+			if got != v.want[i] {
+				t.Fatal("Invalid violation", i, got, v)
+			}
+		}
+	}
+}